@@ -0,0 +1,34 @@
+package wellnessliving
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// AttendanceListRequest is the input for "/Wl/Attendance/AttendanceList.json".
+type AttendanceListRequest struct {
+	ClassPeriodID Integer  `wl:"k_class_period"`
+	Date          DateTime `wl:"dt_date"` // The start time of the session.
+}
+
+// GetAttendanceList returns the roster of a class session.
+//
+// The response has the active list (the clients who are booked), the confirm list (the clients who
+// must confirm their booking), and the wait list.
+func (c *Client) GetAttendanceList(ctx context.Context, classPeriodID Integer, date time.Time) (*AttendanceListResponse, error) {
+	input := AttendanceListRequest{
+		ClassPeriodID: classPeriodID,
+		Date:          DateTime{Time: date},
+	}
+	var output AttendanceListResponse
+	err := c.Request(ctx, http.MethodGet, "/Wl/Attendance/AttendanceList.json", input, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// TODO: Add MarkAttended, MarkNoShow, and UndoAttendance (checking a client in, marking them as a
+// no-show, and undoing either).  These need the SDK's attendance endpoint and its visit status
+// (VisitSid) values, neither of which has been confirmed; they were removed rather than guessed at.
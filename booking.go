@@ -0,0 +1,259 @@
+package wellnessliving
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// ErrBookingDenied is matched by the error from Book when the session cannot be booked.
+var ErrBookingDenied = errors.New("wellnessliving: booking denied")
+
+// BookingDeniedError is returned when a class session cannot be booked.
+type BookingDeniedError struct {
+	Reason  DenyReasonSID // The reason, from ClassSessionClass.DenyReasonID.  This is zero if the session is simply full.
+	Message string        // The reason as shown to the client; this is HTML.
+}
+
+func (e *BookingDeniedError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s: %s", ErrBookingDenied.Error(), e.Message)
+	}
+	if e.Reason != 0 {
+		return fmt.Sprintf("%s: reason %d", ErrBookingDenied.Error(), e.Reason)
+	}
+	return ErrBookingDenied.Error()
+}
+
+func (e *BookingDeniedError) Unwrap() error {
+	return ErrBookingDenied
+}
+
+// BookingCheck is the result of CheckBooking.
+type BookingCheck struct {
+	Session    *ClassSessionDetail // The session, as seen by the client.
+	CanBook    bool                // True if the client can book a spot in the session.
+	IsFull     bool                // True if the session has no spots left.
+	CanWait    bool                // True if the session is full and its wait list has room.
+	DenyReason DenyReasonSID       // If the client cannot book the session, this is why; see ClassSessionClass.DenyReasonID.
+	DenyHTML   string              // If the client cannot book the session, this is why (as shown to the client).
+}
+
+// CheckBooking checks whether the client can book the class session.
+func (c *Client) CheckBooking(ctx context.Context, session SessionKey, uid Integer) (*BookingCheck, error) {
+	sessions, err := c.getClassSessions(ctx, []SessionKey{session}, uid)
+	if err != nil {
+		return nil, err
+	}
+	detail, ok := sessions[session.Normalize()]
+	if !ok {
+		return nil, fmt.Errorf("wellnessliving: class period %d at %s: %w", session.ClassPeriodID, session.Date.Format(dateTimeFormat), ErrNotFound)
+	}
+
+	class := detail.Class
+	check := &BookingCheck{
+		Session:    detail,
+		CanBook:    bool(class.CanBook),
+		IsFull:     class.Capacity > 0 && class.BookActive >= class.Capacity,
+		DenyReason: DenyReasonSID(class.DenyReasonID),
+		DenyHTML:   class.HTMLDenyReason,
+	}
+	// A full session can be waited for if its wait list has room.  The deny reason is not consulted,
+	// since a full session is denied too; if the client is denied for some other reason, then
+	// WellnessLiving will refuse to put them on the wait list.
+	if check.IsFull && bool(class.IsWaitListEnabled) {
+		check.CanWait = class.WaitLimit == nil || *class.WaitLimit == 0 || class.Wait < *class.WaitLimit
+	}
+	return check, nil
+}
+
+// PurchaseOption is a way for a client to pay for a booking.
+type PurchaseOption struct {
+	PromotionID      Integer // The promotion (pass or membership).
+	LoginPromotionID Integer // If the client already owns the promotion, this is their purchase of it.  If zero, the promotion must be bought first.
+	Title            string  // The title of the promotion.
+	StartDate        Date    // If the client owns the promotion, this is when it started.
+	EndDate          Date    // If the client owns the promotion, this is when it ends (if it does).
+	IsDefault        bool    // True if this is the business's default promotion for the class.
+}
+
+// ClassPromotionRequest is the input for "/Wl/Classes/Promotion/ClassPromotion.json".
+type ClassPromotionRequest struct {
+	BusinessID    Integer `wl:"k_business,omitempty"`
+	ClassID       Integer `wl:"k_class,omitempty"`
+	ClassPeriodID Integer `wl:"k_class_period,omitempty"`
+}
+
+// MemberByPromotionRequest is the input for "/Wl/Member/Purchase/MemberByPromotion.json".
+type MemberByPromotionRequest struct {
+	BusinessID   Integer   `wl:"k_business,omitempty"`
+	PromotionIDs []Integer `wl:"a_promotion,omitempty"` // Only purchases of these promotions are listed.
+	UIDs         []Integer `wl:"a_uid,omitempty"`       // The clients to list the purchases of.
+}
+
+// ListPurchaseOptions lists the ways that a client can pay for a session of the class.
+//
+// The options are in order of preference: the promotions that the client already owns come
+// first (the one that ends soonest first), followed by the ones that would have to be bought.
+// Purchases that have ended or been terminated are skipped, and a promotion that the client
+// already owns is not listed again as one to buy.
+func (c *Client) ListPurchaseOptions(ctx context.Context, businessID Integer, classID Integer, uid Integer) ([]PurchaseOption, error) {
+	var classPromotions ClassesPromotionClassPromotionResponse
+	err := c.Request(ctx, http.MethodGet, "/Wl/Classes/Promotion/ClassPromotion.json", ClassPromotionRequest{BusinessID: businessID, ClassID: classID}, nil, &classPromotions)
+	if err != nil {
+		return nil, err
+	}
+	if len(classPromotions.Promotions) == 0 {
+		return nil, nil
+	}
+
+	titles := map[Integer]string{}
+	var promotionIDs []Integer
+	for _, promotion := range classPromotions.Promotions {
+		titles[promotion.PromotionID] = promotion.TextTitle
+		promotionIDs = append(promotionIDs, promotion.PromotionID)
+	}
+	isDefault := func(promotionID Integer) bool {
+		return classPromotions.DefaultPromotionID != nil && *classPromotions.DefaultPromotionID == promotionID
+	}
+
+	var memberPurchases MemberPurchaseMemberByPromotionResponse
+	err = c.Request(ctx, http.MethodGet, "/Wl/Member/Purchase/MemberByPromotion.json", MemberByPromotionRequest{BusinessID: businessID, PromotionIDs: promotionIDs, UIDs: []Integer{uid}}, nil, &memberPurchases)
+	if err != nil {
+		return nil, err
+	}
+
+	today := truncateToDate(c.now())
+	var owned []PurchaseOption
+	ownedPromotionIDs := map[Integer]bool{}
+	for _, client := range memberPurchases.Clients {
+		if client.UID != uid {
+			continue
+		}
+		for _, purchase := range client.PurchaseOptions {
+			if _, ok := titles[purchase.PromotionID]; !ok {
+				continue
+			}
+			if !purchase.EndDate.IsZero() && purchase.EndDate.Before(today) {
+				continue
+			}
+			if purchase.TerminateDate != nil && !purchase.TerminateDate.IsZero() {
+				continue
+			}
+			ownedPromotionIDs[purchase.PromotionID] = true
+			owned = append(owned, PurchaseOption{
+				PromotionID:      purchase.PromotionID,
+				LoginPromotionID: purchase.LoginPromotionID,
+				Title:            titles[purchase.PromotionID],
+				StartDate:        purchase.StartDate,
+				EndDate:          purchase.EndDate,
+				IsDefault:        isDefault(purchase.PromotionID),
+			})
+		}
+	}
+	// The ones that end soonest come first; the ones that never end come last.
+	sort.SliceStable(owned, func(i, j int) bool {
+		if owned[i].EndDate.IsZero() || owned[j].EndDate.IsZero() {
+			return !owned[i].EndDate.IsZero()
+		}
+		return owned[i].EndDate.Before(owned[j].EndDate.Time)
+	})
+
+	options := owned
+	for _, promotion := range classPromotions.Promotions {
+		if ownedPromotionIDs[promotion.PromotionID] {
+			continue
+		}
+		options = append(options, PurchaseOption{
+			PromotionID: promotion.PromotionID,
+			Title:       promotion.TextTitle,
+			IsDefault:   isDefault(promotion.PromotionID),
+		})
+	}
+	return options, nil
+}
+
+// BookingRequest is the input for Book.
+type BookingRequest struct {
+	Session        SessionKey      // The session to book.
+	UID            Integer         // The client to book.
+	PurchaseOption *PurchaseOption // If set, this is used to pay for the booking; it must be owned by the client.  If nil, WellnessLiving chooses.
+	NoWaitList     bool            // If true, do not join the wait list when the session is full.
+}
+
+// BookProcessRequest is the input for "/Wl/Book/Process/Info/Info.json".
+type BookProcessRequest struct {
+	ClassPeriodID    Integer  `wl:"k_class_period"`
+	Date             DateTime `wl:"dt_date_gmt"`
+	UID              Integer  `wl:"uid"`
+	LoginPromotionID Integer  `wl:"k_login_promotion,omitempty"`
+	IsWait           bool     `wl:"is_wait,omitempty"` // If true, join the wait list.
+}
+
+// BookProcessResponse is the response from "/Wl/Book/Process/Info/Info.json".
+type BookProcessResponse struct {
+	BaseResponse
+
+	VisitIDs []Integer `json:"a_visit"`
+	IsWait   Bool      `json:"is_wait"`
+}
+
+// Booking is the result of Book.
+type Booking struct {
+	VisitIDs   []Integer // The visits that were created; use these to cancel the booking.
+	IsWaitList bool      // True if the client was put on the wait list.
+}
+
+// Book books a client into a class session.
+//
+// If the session is full, then the client is put on the wait list (unless NoWaitList is set).  If
+// the client cannot book the session, the error is a *BookingDeniedError.
+func (c *Client) Book(ctx context.Context, request BookingRequest) (*Booking, error) {
+	if request.PurchaseOption != nil && request.PurchaseOption.LoginPromotionID == 0 {
+		return nil, fmt.Errorf("wellnessliving: promotion %d must be bought before it can be used to book", request.PurchaseOption.PromotionID)
+	}
+
+	check, err := c.CheckBooking(ctx, request.Session, request.UID)
+	if err != nil {
+		return nil, err
+	}
+	wait := false
+	switch {
+	case check.IsFull && check.CanWait && !request.NoWaitList:
+		wait = true
+	case !check.CanBook || check.IsFull:
+		return nil, &BookingDeniedError{Reason: check.DenyReason, Message: check.DenyHTML}
+	}
+
+	input := BookProcessRequest{
+		ClassPeriodID: request.Session.ClassPeriodID,
+		Date:          request.Session.Normalize().Date,
+		UID:           request.UID,
+		IsWait:        wait,
+	}
+	if request.PurchaseOption != nil {
+		input.LoginPromotionID = request.PurchaseOption.LoginPromotionID
+	}
+	var output BookProcessResponse
+	err = c.Request(ctx, http.MethodPost, "/Wl/Book/Process/Info/Info.json", input, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+	return &Booking{
+		VisitIDs:   output.VisitIDs,
+		IsWaitList: bool(output.IsWait) || wait,
+	}, nil
+}
+
+// VisitCancelRequest is the input for "/Wl/Visit/Cancel/Cancel.json".
+type VisitCancelRequest struct {
+	VisitID Integer `wl:"k_visit"`
+}
+
+// CancelVisit cancels a booking (or a spot on the wait list).
+func (c *Client) CancelVisit(ctx context.Context, visitID Integer) error {
+	var output BaseResponse
+	return c.Request(ctx, http.MethodPost, "/Wl/Visit/Cancel/Cancel.json", VisitCancelRequest{VisitID: visitID}, nil, &output)
+}
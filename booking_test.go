@@ -0,0 +1,132 @@
+package wellnessliving_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/wellnessliving"
+	"github.com/tekkamanendless/wellnessliving/wltest"
+)
+
+func TestListPurchaseOptions(t *testing.T) {
+	server := wltest.NewServer()
+	defer server.Close()
+
+	date := func(days int) string {
+		return time.Now().AddDate(0, 0, days).Format("2006-01-02")
+	}
+	server.SetFixture("/Wl/Classes/Promotion/ClassPromotion.json", map[string]interface{}{
+		"status": "ok",
+		"a_promotion": []map[string]interface{}{
+			{"k_promotion": "1", "text_title": "Monthly"},
+			{"k_promotion": "2", "text_title": "Ten Pack"},
+			{"k_promotion": "3", "text_title": "Drop In"},
+			{"k_promotion": "4", "text_title": "Annual"},
+		},
+		"k_promotion_default": "3",
+	})
+	server.SetFixture("/Wl/Member/Purchase/MemberByPromotion.json", map[string]interface{}{
+		"status": "ok",
+		"a_clients": []map[string]interface{}{
+			{
+				"uid": "9001",
+				"a_purchase_options": []map[string]interface{}{
+					{"k_promotion": "1", "k_login_promotion": "101", "dl_start": date(-10), "dl_end": date(20), "dl_terminate": nil},
+					{"k_promotion": "1", "k_login_promotion": "102", "dl_start": date(-10), "dl_end": date(5), "dl_terminate": nil},
+					{"k_promotion": "2", "k_login_promotion": "103", "dl_start": date(-40), "dl_end": date(-1), "dl_terminate": nil},          // Expired.
+					{"k_promotion": "4", "k_login_promotion": "104", "dl_start": date(-40), "dl_end": "", "dl_terminate": date(-2)},           // Terminated.
+					{"k_promotion": "5", "k_login_promotion": "105", "dl_start": date(-10), "dl_end": date(30), "dl_terminate": nil},          // Not for this class.
+					{"k_promotion": "1", "k_login_promotion": "106", "dl_start": date(-10), "dl_end": "", "dl_terminate": nil},                // Never ends.
+					{"k_promotion": "3", "k_login_promotion": "107", "dl_start": date(-10), "dl_end": date(0), "dl_terminate": nil},           // Ends today.
+					{"k_promotion": "4", "k_login_promotion": "108", "dl_start": date(-10), "dl_end": date(10), "dl_terminate": "0000-00-00"}, // Not terminated.
+				},
+			},
+			{
+				"uid": "9002",
+				"a_purchase_options": []map[string]interface{}{
+					{"k_promotion": "3", "k_login_promotion": "201", "dl_start": date(-10), "dl_end": date(20), "dl_terminate": nil},
+				},
+			},
+		},
+	})
+
+	client := server.Client()
+	options, err := client.ListPurchaseOptions(context.Background(), 1000, 50, 9001)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var actual []wellnessliving.Integer
+	for _, option := range options {
+		actual = append(actual, option.LoginPromotionID)
+		if option.LoginPromotionID == 0 {
+			actual[len(actual)-1] = -option.PromotionID
+		}
+	}
+	// Owned purchases are listed by their purchase ID, soonest end first; promotions to buy are listed by their negated promotion ID.
+	// Promotion 2 must be bought, since its only purchase has expired.
+	expected := []wellnessliving.Integer{107, 102, 108, 101, 106, -2}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	for _, option := range options {
+		if option.IsDefault != (option.PromotionID == 3) {
+			t.Errorf("Promotion %d: expected IsDefault to be %t", option.PromotionID, option.PromotionID == 3)
+		}
+	}
+}
+
+func TestBookDenied(t *testing.T) {
+	server := wltest.NewServer()
+	defer server.Close()
+	server.SetFixture("/Wl/Schedule/ClassView/ClassView.json", map[string]interface{}{
+		"status": "ok",
+		"a_session_result": []map[string]interface{}{
+			{
+				"k_class_period": "50",
+				"dt_date":        "2024-03-01 15:00:00",
+				"a_class": map[string]interface{}{
+					"can_book":         false,
+					"i_capacity":       "10",
+					"i_book_active":    "2",
+					"id_deny_reason":   "3",
+					"html_deny_reason": "You need a membership.",
+				},
+			},
+		},
+	})
+
+	client := server.Client()
+	session := wellnessliving.SessionKey{
+		ClassPeriodID: 50,
+		Date:          wellnessliving.DateTime{Time: time.Date(2024, time.March, 1, 15, 0, 0, 0, time.UTC)},
+	}
+	check, err := client.CheckBooking(context.Background(), session, 9001)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if check.CanBook || check.IsFull || check.CanWait {
+		t.Errorf("Expected the session to be denied but not full, got %+v", check)
+	}
+	if check.DenyReason != wellnessliving.DenyReasonSID(3) {
+		t.Errorf("Expected deny reason 3, got %d", check.DenyReason)
+	}
+
+	_, err = client.Book(context.Background(), wellnessliving.BookingRequest{Session: session, UID: 9001})
+	var deniedError *wellnessliving.BookingDeniedError
+	if !errors.As(err, &deniedError) {
+		t.Fatalf("Expected a BookingDeniedError, got %v", err)
+	}
+	if !errors.Is(err, wellnessliving.ErrBookingDenied) {
+		t.Errorf("Expected ErrBookingDenied, got %v", err)
+	}
+	if deniedError.Reason != check.DenyReason || deniedError.Message != check.DenyHTML {
+		t.Errorf("Expected the reason from the check, got %+v", deniedError)
+	}
+	if count := server.Requests("/Wl/Book/Process/Info/Info.json"); count != 0 {
+		t.Errorf("Expected no booking requests, got %d", count)
+	}
+}
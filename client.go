@@ -3,19 +3,18 @@ package wellnessliving
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"net/http/cookiejar"
 	"net/http/httputil"
 	"net/url"
-	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"github.com/tekkamanendless/httperror"
-	"golang.org/x/crypto/sha3"
 )
 
 // Client is the WellnessLiving client.
@@ -27,19 +26,43 @@ import (
 // * WELLNESSLIVING_AUTHORIZATION_CODE
 // * WELLNESSLIVING_AUTHORIZATION_ID
 //
+// Alternatively, set Credentials to load them from somewhere else (such as a file that may change).
+//
 // If you wish to use the WellnessLiving staging API, then you will need to set URL, as well.
 type Client struct {
-	URL               string      // The base URL.  If empty, this will use the WellnessLiving production URL.
-	AuthorizationCode string      // This is your authorization code.  If not set, the value of WELLNESSLIVING_AUTHORIZATION_CODE will be used.
-	AuthorizationID   string      // This is your authorization ID.  If not set, the value of WELLNESSLIVING_AUTHORIZATION_CODE will be used.
-	HTTPClient        http.Client // This is the HTTP client.  It's available in case you need to make tweaks.
+	URL               string              // The base URL.  If empty, this will use the WellnessLiving production URL.
+	AuthorizationCode string              // This is your authorization code.  If not set, the value of WELLNESSLIVING_AUTHORIZATION_CODE will be used.
+	AuthorizationID   string              // This is your authorization ID.  If not set, the value of WELLNESSLIVING_AUTHORIZATION_ID will be used.
+	Credentials       CredentialsProvider // If set, this provides the authorization code and ID instead of the fields above.
+	HTTPClient        http.Client         // This is the HTTP client.  It's available in case you need to make tweaks.
+	RetryPolicy       *RetryPolicy        // If set, failed requests are retried according to this policy.
+	RateLimiter       *RateLimiter        // If set, every request (including retries) waits for this limiter first.  This may be shared between clients.
+	Timeout           time.Duration       // If set, this is the timeout for each call whose context does not already have a deadline.
+	SessionStore      SessionStore        // If set, Login saves the session here and reuses it (instead of logging in again) until it expires.
+	Clock             Clock               // If set, this is used instead of the system clock.  The client corrects it using the server's clock; see ClockOffset.
+
+	// SignedHeaders are the names of the request headers that are part of the signature.
+	//
+	// By default, no headers are signed, which is what this client has always done.  Only set this if
+	// the API requires it; see signature_test.go for how the headers are signed.
+	//
+	// TODO: Default this to the header list from the SDK's WlModelRequest once it has been confirmed.
+	SignedHeaders []string
+
+	// LoginCredentials, if set, is used to log in again when a request fails because the session has expired.
+	LoginCredentials LoginCredentialsProvider
+
+	username       string       // This is the user that is logged in, if any.
+	authMutex      sync.Mutex   // This is held while logging in.
+	authGeneration uint64       // This is incremented every time that the client logs in.
+	clockOffset    atomic.Int64 // This is the difference between the server's clock and ours, in nanoseconds.
 }
 
 // Signature contains all of the pieces of information needed to compute the signature verification
 // that is needed for every API request.
 type Signature struct {
 	Header            http.Header
-	Variables         url.Values
+	Variables         url.Values // The variables exactly as they are sent; see EncodeVariables.
 	Time              time.Time
 	AuthorizationCode string
 	CookiePersistent  string
@@ -56,49 +79,116 @@ type Signature struct {
 //
 // After logging in, the client will be authenticated for all future requests using the client's
 // cookie jar as part of HTTPClient.
+//
+// If the client has a SessionStore with an unexpired session for the same user, then that session
+// is restored and checked with WellnessLiving; if it is still logged in, then it is used instead of
+// logging in again.  Otherwise, the new session is saved to the store.
+//
+// This is the same as LoginWithOptions with the default options.
 func (c *Client) Login(ctx context.Context, username string, password string) error {
-	jar, err := cookiejar.New(nil)
+	_, err := c.LoginWithOptions(ctx, username, password, LoginOptions{})
+	return err
+}
+
+// LoginWithOptions is Login with options; see LoginOptions.
+//
+// This returns the response from the Enter endpoint, which has the URL that WellnessLiving would
+// redirect a browser to.  If a saved session was restored, then the response is empty.
+//
+// If WellnessLiving requires a captcha, then this returns a *CaptchaRequiredError, which matches
+// ErrCaptchaRequired.
+func (c *Client) LoginWithOptions(ctx context.Context, username string, password string, options LoginOptions) (*EnterResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+
+	if c.SessionStore != nil {
+		restored, err := c.restoreStoredSession(ctx, username)
+		if err != nil {
+			logrus.WithContext(ctx).Warnf("Could not restore the saved session: %v", err)
+		} else if restored {
+			valid, err := c.validateSession(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if valid {
+				logrus.WithContext(ctx).Debugf("Restored the saved session for %q.", username)
+				c.authGeneration++
+				return &EnterResponse{}, nil
+			}
+			logrus.WithContext(ctx).Debugf("The saved session for %q is no longer logged in; logging in again.", username)
+		}
+	}
+
+	_, err := c.emptyJar()
 	if err != nil {
-		return fmt.Errorf("could not create cookie jar: %w", err)
+		return nil, err
 	}
-	c.HTTPClient.Jar = jar
+	c.username = ""
+
+	return c.login(ctx, username, password, options)
+}
+
+// login performs the Notepad/Enter handshake using the client's current cookie jar.
+//
+// The caller must hold authMutex.
+func (c *Client) login(ctx context.Context, username string, password string, options LoginOptions) (*EnterResponse, error) {
+	c.authGeneration++
 
+	notepadInput := url.Values{}
+	notepadInput.Set("s_login", username)
 	var notepadResponse NotepadResponse
-	err = c.Request(ctx, http.MethodGet, "/Core/Passport/Login/Enter/Notepad.json", nil, nil, &notepadResponse)
+	err := c.request(ctx, http.MethodGet, "/Core/Passport/Login/Enter/Notepad.json", notepadInput, nil, &notepadResponse)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var hashedPassword string
-	switch notepadResponse.Hash {
-	case "sha3":
-		parts := []string{
-			"r",
-			"4S",
-			"zqX",
-			"zqiOK",
-			"TLVS75V",
-			"Ue5aLaIIG75",
-			"uODJYM2JsCX4G",
-			"kt58wZfHHGQkHW4QN",
-			"Lh9Fl5989crMU4E7P6E",
+	if !options.NoRegionRedirect {
+		regionURL := c.regionURL(notepadResponse.RegionID)
+		if regionURL != "" && regionURL != c.baseURL() {
+			// The account lives in another region, so start over there.
+			logrus.WithContext(ctx).Debugf("Switching to the account's region: %s", regionURL)
+			c.URL = regionURL
+			options.NoRegionRedirect = true
+			return c.login(ctx, username, password, options)
 		}
-		hashedPassword = fmt.Sprintf("%x", sha3.Sum512([]byte(notepadResponse.Notepad+fmt.Sprintf("%x", sha3.Sum512([]byte(strings.Join(parts, password)+password))))))
+	}
+
+	hashedPassword, err := HashPassword(notepadResponse.Hash, notepadResponse.Notepad, password)
+	if err != nil {
+		return nil, err
 	}
 
 	enterInput := url.Values{}
-	enterInput.Set("s_captcha", "")
+	enterInput.Set("s_captcha", options.Captcha)
 	enterInput.Set("s_login", username)
 	enterInput.Set("s_notepad", notepadResponse.Notepad)
 	enterInput.Set("s_password", hashedPassword)
-	enterInput.Set("s_remember", "")
+	if options.Remember {
+		enterInput.Set("s_remember", "1")
+	} else {
+		enterInput.Set("s_remember", "")
+	}
 	var enterResponse EnterResponse
-	err = c.Request(ctx, http.MethodPost, "/Core/Passport/Login/Enter/Enter.json", enterInput, nil, &enterResponse)
+	err = c.request(ctx, http.MethodPost, "/Core/Passport/Login/Enter/Enter.json", enterInput, nil, &enterResponse)
 	if err != nil {
-		return err
+		var apiError *APIError
+		// The Enter endpoint is only about logging in, so any mention of a captcha means that one is required.
+		if errors.As(err, &apiError) && apiError.mentions("captcha") {
+			return nil, newCaptchaRequiredError(apiError)
+		}
+		return nil, err
 	}
+	c.username = username
 
-	return nil
+	err = c.saveSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &enterResponse, nil
 }
 
 // Request performs and API request.
@@ -106,7 +196,38 @@ func (c *Client) Login(ctx context.Context, username string, password string) er
 // variables is what WellnessLiving refers to as such.  These will be used as query parameters.
 // For requests that normally expect a body (such as POST), they will be converted to form values
 // and the encoding will be set appropriately.
-func (c *Client) Request(ctx context.Context, method string, path string, variables url.Values, input interface{}, output interface{}) error {
+// variables may be a url.Values or anything else that EncodeVariables supports, such as a struct
+// with `wl` tags.
+//
+// input, if not nil, is the body.  A string is used as-is; anything else is encoded as a form
+// using EncodeVariables.
+//
+// If the client has LoginCredentials and the request fails because it was not authenticated, then
+// the client logs in again and replays the request once.
+func (c *Client) Request(ctx context.Context, method string, path string, variables interface{}, input interface{}, output interface{}) error {
+	encodedVariables, err := EncodeVariables(variables)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	generation := c.loginGeneration()
+	err = c.request(ctx, method, path, encodedVariables, input, output)
+	if err != nil && c.LoginCredentials != nil && errors.Is(err, ErrNotAuthenticated) {
+		logrus.WithContext(ctx).Debugf("The request was not authenticated; logging in again: %v", err)
+		loginErr := c.relogin(ctx, generation)
+		if loginErr != nil {
+			return fmt.Errorf("wellnessliving: could not log in again after %v: %w", err, loginErr)
+		}
+		err = c.request(ctx, method, path, encodedVariables, input, output)
+	}
+	return err
+}
+
+// request performs an API request.
+func (c *Client) request(ctx context.Context, method string, path string, variables url.Values, input interface{}, output interface{}) error {
 	var bodyString string
 	header := http.Header{}
 	if input == nil {
@@ -122,32 +243,61 @@ func (c *Client) Request(ctx context.Context, method string, path string, variab
 		if v, ok := input.(string); ok {
 			// The input is a string; use it as-is.
 			bodyString = v
-		} else if v, ok := input.(url.Values); ok {
+		} else {
 			// The input is a collection of values; encode it as a form.
-			bodyString = v.Encode()
+			values, err := EncodeVariables(input)
+			if err != nil {
+				return err
+			}
+			bodyString = values.Encode()
 			header.Set("Content-Type", "application/x-www-form-urlencoded")
 		}
 	}
 
-	contents, err := c.Raw(ctx, method, path, variables, bodyString, header)
-	if err != nil {
-		return err
-	}
-
-	var baseResponse BaseResponse
-	err = json.Unmarshal(contents, &baseResponse)
-	if err != nil {
-		return fmt.Errorf("wellnessliving: could not parse response envelope: %w", err)
-	}
+	var contents []byte
+	perform := func() error {
+		var baseResponse BaseResponse
+		err := c.retry(ctx, method, func() error {
+			var err error
+			contents, err = c.rawAttempt(ctx, method, path, variables, bodyString, header)
+			if err != nil {
+				return err
+			}
 
-	logrus.WithContext(ctx).Debugf("Envelope: %+v", baseResponse)
-	if baseResponse.Status != "ok" {
-		var errorResponse ErrorResponse
-		err = json.Unmarshal(contents, &errorResponse)
+			err = json.Unmarshal(contents, &baseResponse)
+			if err != nil {
+				// A response without a valid envelope is usually a truncated response or an error page from
+				// something between us and WellnessLiving.
+				return &transientError{err: fmt.Errorf("wellnessliving: could not parse response envelope: %w", err)}
+			}
+			return nil
+		})
 		if err != nil {
-			return fmt.Errorf("wellnessliving: could not parse error response: %w", err)
+			return err
 		}
-		return &errorResponse
+
+		logrus.WithContext(ctx).Debugf("Envelope: %+v", baseResponse)
+		if baseResponse.Status != "ok" {
+			return newAPIError(strings.ToUpper(method), path, http.StatusOK, contents)
+		}
+		return nil
+	}
+
+	offset := c.ClockOffset()
+	err := perform()
+	var apiError *APIError
+	if err != nil && errors.As(err, &apiError) && c.ClockOffset() != offset {
+		// The request was signed with a clock that we now know to be off, so the rejection was probably
+		// because of the signature; try again with the corrected clock.
+		//
+		// This does not check for a signature error code, since the code that the API uses for one has not
+		// been confirmed (see signatureInvalidCodes); the offset only changes when our clock is wrong, so
+		// any API error will do.
+		logrus.WithContext(ctx).Debugf("The request was rejected after the clock offset changed; retrying with a clock offset of %v: %v", c.ClockOffset(), err)
+		err = perform()
+	}
+	if err != nil {
+		return err
 	}
 
 	if output != nil {
@@ -162,7 +312,8 @@ func (c *Client) Request(ctx context.Context, method string, path string, variab
 
 // Raw performs a raw request and returns any response content.
 //
-// If the response is an error response, then the appropriate `httperror` response will be returned.
+// If the response is an error response, then an *APIError will be returned; this unwraps to the
+// appropriate `httperror` response.
 //
 // variables will be used as query parameters.
 // bodyString, if not empty, will be used as the body.  Please ensure that the "Content-Type" header is set appropriately.
@@ -173,11 +324,32 @@ func (c *Client) Request(ctx context.Context, method string, path string, variab
 // * Date
 // * User-Agent
 // * Authorization
+//
+// If the client has a RetryPolicy, failed attempts are retried according to it.
+//
+// If the context is canceled or its deadline passes, then the returned error will match
+// context.Canceled or context.DeadlineExceeded (respectively) with errors.Is.
 func (c *Client) Raw(ctx context.Context, method string, path string, variables url.Values, bodyString string, header http.Header) ([]byte, error) {
-	baseURL := c.URL
-	if baseURL == "" {
-		baseURL = "https://us.wellnessliving.com"
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	var contents []byte
+	err := c.retry(ctx, method, func() error {
+		var err error
+		contents, err = c.rawAttempt(ctx, method, path, variables, bodyString, header)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return contents, nil
+}
+
+// rawAttempt performs a single attempt of a raw request.
+//
+// Each attempt is signed separately, since the signature depends on the current time.
+func (c *Client) rawAttempt(ctx context.Context, method string, path string, variables url.Values, bodyString string, header http.Header) ([]byte, error) {
+	baseURL := c.baseURL()
 
 	targetURL := path
 	if !strings.Contains(targetURL, "://") {
@@ -192,6 +364,14 @@ func (c *Client) Raw(ctx context.Context, method string, path string, variables
 		return nil, fmt.Errorf("wellnessliving: could not parse URL: %w", err)
 	}
 
+	// Wait for the rate limiter before signing, since the signature depends on the time.
+	if c.RateLimiter != nil {
+		err = c.RateLimiter.Wait(ctx, myURL.Host)
+		if err != nil {
+			return nil, fmt.Errorf("wellnessliving: could not wait for rate limiter: %w", err)
+		}
+	}
+
 	method = strings.ToUpper(method)
 
 	var body io.Reader
@@ -199,7 +379,7 @@ func (c *Client) Raw(ctx context.Context, method string, path string, variables
 		body = strings.NewReader(bodyString)
 	}
 
-	request, err := http.NewRequest(method, targetURL, body)
+	request, err := http.NewRequestWithContext(ctx, method, targetURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("wellnessliving: could not create request: %w", err)
 	}
@@ -213,26 +393,29 @@ func (c *Client) Raw(ctx context.Context, method string, path string, variables
 	if err != nil {
 		return nil, fmt.Errorf("wellnessliving: could not load timezone: %w", err)
 	}
-	now := time.Now().In(tz)
+	now := c.now().In(tz)
 
-	authorizationCode := c.AuthorizationCode
-	if authorizationCode == "" {
-		authorizationCode = os.Getenv("WELLNESSLIVING_AUTHORIZATION_CODE")
+	credentials, err := c.credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("wellnessliving: could not get credentials: %w", err)
 	}
-	authorizationID := c.AuthorizationID
-	if authorizationID == "" {
-		authorizationID = os.Getenv("WELLNESSLIVING_AUTHORIZATION_ID")
+
+	request.Header.Set("Accept", "*/*")
+	request.Header.Set("Date", now.Format(time.RFC1123))
+	request.Header.Set("User-Agent", "WellnessLiving SDK/1.1 (WellnessLiving SDK)")
+	if bodyString != "" && bodyString[0] == '{' {
+		request.Header.Set("Content-Type", "application/json")
 	}
 
 	signature := Signature{
 		Header:            http.Header{},
 		Variables:         variables,
 		Time:              now,
-		AuthorizationCode: authorizationCode,
+		AuthorizationCode: credentials.AuthorizationCode,
 		CookiePersistent:  "", // Default these to empty for now.
 		CookieTransient:   "", // Default these to empty for now.
 		Host:              myURL.Host,
-		AuthorizationID:   authorizationID,
+		AuthorizationID:   credentials.AuthorizationID,
 		Method:            strings.ToUpper(method),
 		Resource:          strings.TrimLeft(path, "/"),
 	}
@@ -251,37 +434,66 @@ func (c *Client) Raw(ctx context.Context, method string, path string, variables
 			}
 		}
 	}
+	for _, key := range c.SignedHeaders {
+		for _, value := range request.Header.Values(key) {
+			signature.Header.Add(key, value)
+		}
+	}
 	authorization := computeAuthorizationHash(signature)
-
-	request.Header.Set("Accept", "*/*")
-	request.Header.Set("Date", now.Format(time.RFC1123))
-	request.Header.Set("User-Agent", "WellnessLiving SDK/1.1 (WellnessLiving SDK)")
 	request.Header.Set("Authorization", authorization)
-	if bodyString != "" && bodyString[0] == '{' {
-		request.Header.Set("Content-Type", "application/json")
-	}
 
 	{
 		contents, _ := httputil.DumpRequest(request, true)
 		logrus.WithContext(ctx).Debugf("REQUEST:\n%s\n", contents)
 	}
 
+	sent := c.clock().Now()
 	response, err := c.HTTPClient.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("wellnessliving: could not perform request: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("wellnessliving: could not perform request: %w", ctx.Err())
+		}
+		return nil, &transientError{err: fmt.Errorf("wellnessliving: could not perform request: %w", err)}
 	}
+	defer response.Body.Close()
+	c.updateClockOffset(response.Header.Get("Date"), sent, c.clock().Now())
 
 	logrus.WithContext(ctx).Debugf("Status code: %d", response.StatusCode)
 	if response.StatusCode >= 400 {
-		return nil, httperror.ErrorFromStatus(response.StatusCode)
+		contents, _ := io.ReadAll(response.Body)
+		apiError := newAPIError(method, path, response.StatusCode, contents)
+		apiError.retryAfter = parseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+		return nil, apiError
 	}
 
 	contents, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, fmt.Errorf("wellnessliving: could not read response body: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("wellnessliving: could not read response body: %w", ctx.Err())
+		}
+		return nil, &transientError{err: fmt.Errorf("wellnessliving: could not read response body: %w", err)}
 	}
 	logrus.WithContext(ctx).Debugf("Response:")
 	logrus.WithContext(ctx).Debugf("%s", contents)
 
 	return contents, nil
 }
+
+// withTimeout applies the client's default timeout to the context, if the context doesn't already have a deadline.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+// baseURL returns the base URL of the API.
+func (c *Client) baseURL() string {
+	if c.URL == "" {
+		return "https://us.wellnessliving.com"
+	}
+	return c.URL
+}
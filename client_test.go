@@ -0,0 +1,560 @@
+package wellnessliving_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/wellnessliving"
+	"github.com/tekkamanendless/wellnessliving/wltest"
+)
+
+const (
+	notepadPath      = "/Core/Passport/Login/Enter/Notepad.json"
+	enterPath        = "/Core/Passport/Login/Enter/Enter.json"
+	locationListPath = "/Wl/Location/List.json"
+)
+
+func TestLogin(t *testing.T) {
+	server := wltest.NewServer()
+	defer server.Close()
+	uid := server.AddUser("jane@example.com", "secret")
+
+	t.Run("Success", func(t *testing.T) {
+		client := server.Client()
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		currentUser, err := client.CurrentUser(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if int(currentUser.UID) != uid {
+			t.Errorf("Expected UID %d, got %d", uid, currentUser.UID)
+		}
+		if currentUser.Login != "jane@example.com" {
+			t.Errorf("Expected login %q, got %q", "jane@example.com", currentUser.Login)
+		}
+	})
+	t.Run("WrongPassword", func(t *testing.T) {
+		client := server.Client()
+		err := client.Login(context.Background(), "jane@example.com", "wrong")
+		if !errors.Is(err, wellnessliving.ErrNotAuthenticated) {
+			t.Errorf("Expected ErrNotAuthenticated, got %v", err)
+		}
+	})
+	t.Run("UnknownUser", func(t *testing.T) {
+		client := server.Client()
+		err := client.Login(context.Background(), "john@example.com", "secret")
+		if !errors.Is(err, wellnessliving.ErrNotAuthenticated) {
+			t.Errorf("Expected ErrNotAuthenticated, got %v", err)
+		}
+	})
+}
+
+func TestSavedSession(t *testing.T) {
+	const infoPath = "/Core/Passport/Login/Info/Info.json"
+
+	t.Run("Valid", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		store := &wellnessliving.MemorySessionStore{}
+
+		client := server.Client()
+		client.SessionStore = store
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		client = server.Client()
+		client.SessionStore = store
+		err = client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if count := server.Requests(enterPath); count != 1 {
+			t.Errorf("Expected 1 login, got %d", count)
+		}
+		if count := server.Requests(infoPath); count != 1 {
+			t.Errorf("Expected the restored session to be checked once, got %d", count)
+		}
+	})
+	t.Run("Ended", func(t *testing.T) {
+		// WellnessLiving ended the session before its cookies expired, so the client logs in again.
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		store := &wellnessliving.MemorySessionStore{}
+
+		client := server.Client()
+		client.SessionStore = store
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		server.ExpireSessions()
+		client = server.Client()
+		client.SessionStore = store
+		err = client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if count := server.Requests(enterPath); count != 2 {
+			t.Errorf("Expected 2 logins, got %d", count)
+		}
+		currentUser, err := client.CurrentUser(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if currentUser.Login != "jane@example.com" {
+			t.Errorf("Expected login %q, got %q", "jane@example.com", currentUser.Login)
+		}
+	})
+	t.Run("OtherUser", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		server.AddUser("john@example.com", "secret")
+		store := &wellnessliving.MemorySessionStore{}
+
+		client := server.Client()
+		client.SessionStore = store
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		client = server.Client()
+		client.SessionStore = store
+		err = client.Login(context.Background(), "john@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if count := server.Requests(enterPath); count != 2 {
+			t.Errorf("Expected 2 logins, got %d", count)
+		}
+		if count := server.Requests(infoPath); count != 0 {
+			t.Errorf("Expected no session check, got %d", count)
+		}
+	})
+}
+
+func TestLogoutWhileRequesting(t *testing.T) {
+	// Logging in and out empties the cookie jar in place, so this is safe (see "go test -race").
+	server := wltest.NewServer()
+	defer server.Close()
+	server.AddUser("jane@example.com", "secret")
+
+	client := server.Client()
+	err := client.Login(context.Background(), "jane@example.com", "secret")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_, _ = client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		err = client.Logout(context.Background())
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		err = client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if session := client.Session(); session == nil || session.Username != "jane@example.com" {
+		t.Errorf("Expected a session for %q, got %+v", "jane@example.com", session)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := &wellnessliving.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}
+
+	t.Run("Recovers", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.Fail(locationListPath, wltest.Failure{StatusCode: http.StatusServiceUnavailable, Times: 2})
+
+		client := server.Client()
+		client.RetryPolicy = policy
+		response, err := client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(response.LocationMap) == 0 {
+			t.Errorf("Expected some locations")
+		}
+		if count := server.Requests(locationListPath); count != 3 {
+			t.Errorf("Expected 3 requests, got %d", count)
+		}
+	})
+	t.Run("GivesUp", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.Fail(locationListPath, wltest.Failure{StatusCode: http.StatusServiceUnavailable, Times: 5})
+
+		client := server.Client()
+		client.RetryPolicy = policy
+		_, err := client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		var apiError *wellnessliving.APIError
+		if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Expected a 503 APIError, got %v", err)
+		}
+		if count := server.Requests(locationListPath); count != 3 {
+			t.Errorf("Expected 3 requests, got %d", count)
+		}
+	})
+	t.Run("NoPolicy", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.Fail(locationListPath, wltest.Failure{StatusCode: http.StatusServiceUnavailable})
+
+		client := server.Client()
+		_, err := client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if err == nil {
+			t.Errorf("Expected an error")
+		}
+		if count := server.Requests(locationListPath); count != 1 {
+			t.Errorf("Expected 1 request, got %d", count)
+		}
+	})
+	t.Run("NotIdempotent", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.Fail(locationListPath, wltest.Failure{StatusCode: http.StatusServiceUnavailable})
+
+		client := server.Client()
+		client.RetryPolicy = policy
+		err := client.Request(context.Background(), http.MethodPost, locationListPath, nil, nil, nil)
+		if err == nil {
+			t.Errorf("Expected an error")
+		}
+		if count := server.Requests(locationListPath); count != 1 {
+			t.Errorf("Expected 1 request, got %d", count)
+		}
+	})
+}
+
+func TestRelogin(t *testing.T) {
+	t.Run("Credentials", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		server.RequireLogin(locationListPath)
+
+		client := server.Client()
+		client.LoginCredentials = wellnessliving.LoginCredentialsFunc(func(ctx context.Context) (string, string, error) {
+			return "jane@example.com", "secret", nil
+		})
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		server.ExpireSessions()
+		_, err = client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if count := server.Requests(enterPath); count != 2 {
+			t.Errorf("Expected 2 logins, got %d", count)
+		}
+		if count := server.Requests(locationListPath); count != 2 {
+			t.Errorf("Expected 2 requests, got %d", count)
+		}
+	})
+	t.Run("NoCredentials", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		server.RequireLogin(locationListPath)
+
+		client := server.Client()
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		server.ExpireSessions()
+		_, err = client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if !errors.Is(err, wellnessliving.ErrNotAuthenticated) {
+			t.Errorf("Expected ErrNotAuthenticated, got %v", err)
+		}
+		if count := server.Requests(enterPath); count != 1 {
+			t.Errorf("Expected 1 login, got %d", count)
+		}
+	})
+	t.Run("Forbidden", func(t *testing.T) {
+		// Logging in again doesn't help with something that the user isn't allowed to do.
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		server.Fail(locationListPath, wltest.Failure{StatusCode: http.StatusForbidden})
+
+		client := server.Client()
+		client.LoginCredentials = wellnessliving.LoginCredentialsFunc(func(ctx context.Context) (string, string, error) {
+			return "jane@example.com", "secret", nil
+		})
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err = client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if !errors.Is(err, wellnessliving.ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+		if errors.Is(err, wellnessliving.ErrNotAuthenticated) {
+			t.Errorf("Expected the error to not be ErrNotAuthenticated: %v", err)
+		}
+		if count := server.Requests(enterPath); count != 1 {
+			t.Errorf("Expected 1 login, got %d", count)
+		}
+		if count := server.Requests(locationListPath); count != 1 {
+			t.Errorf("Expected 1 request, got %d", count)
+		}
+	})
+	t.Run("CredentialsFail", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		server.RequireLogin(locationListPath)
+
+		client := server.Client()
+		client.LoginCredentials = wellnessliving.LoginCredentialsFunc(func(ctx context.Context) (string, string, error) {
+			return "jane@example.com", "changed", nil
+		})
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		server.ExpireSessions()
+		_, err = client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if !errors.Is(err, wellnessliving.ErrNotAuthenticated) {
+			t.Errorf("Expected ErrNotAuthenticated, got %v", err)
+		}
+		if count := server.Requests(locationListPath); count != 1 {
+			t.Errorf("Expected 1 request, got %d", count)
+		}
+	})
+}
+
+func TestSignature(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+
+		client := server.Client()
+		_, err := client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	t.Run("WrongCode", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+
+		client := server.Client()
+		client.Credentials = wellnessliving.StaticCredentials{
+			AuthorizationCode: "wrong",
+			AuthorizationID:   server.AuthorizationID,
+		}
+		_, err := client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if !errors.Is(err, wellnessliving.ErrSignatureInvalid) {
+			t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+		}
+		if errors.Is(err, wellnessliving.ErrNotAuthenticated) {
+			t.Errorf("Expected the error not to be ErrNotAuthenticated: %v", err)
+		}
+	})
+	t.Run("WrongID", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+
+		client := server.Client()
+		client.Credentials = wellnessliving.StaticCredentials{
+			AuthorizationCode: server.AuthorizationCode,
+			AuthorizationID:   "wrong",
+		}
+		_, err := client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if !errors.Is(err, wellnessliving.ErrSignatureInvalid) {
+			t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+		}
+	})
+	t.Run("Cookies", func(t *testing.T) {
+		// After logging in, the "p" and "t" cookies are part of the signature.
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		server.RequireLogin(locationListPath)
+
+		client := server.Client()
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		_, err = client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	t.Run("SignedHeaders", func(t *testing.T) {
+		signedHeaders := []string{"Date", "User-Agent"}
+		verifyOptions := wellnessliving.VerifyOptions{
+			AuthorizationCode: func(authorizationID string) (string, bool) {
+				return "code", authorizationID == "id"
+			},
+			SignedHeaders: signedHeaders,
+		}
+		server := httptest.NewServer(wellnessliving.VerifyMiddleware(verifyOptions, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		})))
+		defer server.Close()
+
+		client := &wellnessliving.Client{
+			URL: server.URL,
+			Credentials: wellnessliving.StaticCredentials{
+				AuthorizationCode: "code",
+				AuthorizationID:   "id",
+			},
+		}
+		err := client.Request(context.Background(), http.MethodGet, "/Wl/Business/Data.json", nil, nil, nil)
+		if !errors.Is(err, wellnessliving.ErrSignatureInvalid) {
+			t.Errorf("Expected ErrSignatureInvalid without the signed headers, got %v", err)
+		}
+
+		client.SignedHeaders = signedHeaders
+		err = client.Request(context.Background(), http.MethodGet, "/Wl/Business/Data.json", nil, nil, nil)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestClockSkew(t *testing.T) {
+	server := wltest.NewServer()
+	defer server.Close()
+
+	// The client's clock is 10 minutes slow, which is past the server's 5-minute limit.
+	skew := 10 * time.Minute
+	client := server.Client()
+	client.Clock = wellnessliving.ClockFunc(func() time.Time {
+		return time.Now().Add(-skew)
+	})
+
+	_, err := client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count := server.Requests(locationListPath); count != 1 {
+		// The first attempt is rejected by the middleware before it reaches the server.
+		t.Errorf("Expected 1 request to reach the server, got %d", count)
+	}
+	if offset := client.ClockOffset(); offset < skew-2*time.Second || offset > skew+2*time.Second {
+		t.Errorf("Expected a clock offset of about %v, got %v", skew, offset)
+	}
+
+	// Now that the offset is known, requests succeed the first time.
+	_, err = client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count := server.Requests(locationListPath); count != 2 {
+		t.Errorf("Expected 2 requests to reach the server, got %d", count)
+	}
+}
+
+func TestClockSkewOtherCode(t *testing.T) {
+	// The retry doesn't depend on the code that the server uses for a rejected signature.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		date, err := http.ParseTime(r.Header.Get("Date"))
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil || time.Since(date) > 5*time.Minute {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"status":"error","message":"The request is not valid."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	skew := 10 * time.Minute
+	client := &wellnessliving.Client{
+		URL: server.URL,
+		Credentials: wellnessliving.StaticCredentials{
+			AuthorizationCode: "code",
+			AuthorizationID:   "id",
+		},
+		Clock: wellnessliving.ClockFunc(func() time.Time {
+			return time.Now().Add(-skew)
+		}),
+	}
+	err := client.Request(context.Background(), http.MethodGet, "/Wl/Business/Data.json", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if offset := client.ClockOffset(); offset < skew-2*time.Second || offset > skew+2*time.Second {
+		t.Errorf("Expected a clock offset of about %v, got %v", skew, offset)
+	}
+}
+
+func TestClockSkewNoDate(t *testing.T) {
+	// Without a "Date" header, there is nothing to correct with, so the signature error stands.
+	verifyOptions := wellnessliving.VerifyOptions{
+		AuthorizationCode: func(authorizationID string) (string, bool) {
+			return "code", authorizationID == "id"
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = nil // This stops net/http from adding one.
+		wellnessliving.VerifyMiddleware(verifyOptions, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		})).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &wellnessliving.Client{
+		URL: server.URL,
+		Credentials: wellnessliving.StaticCredentials{
+			AuthorizationCode: "code",
+			AuthorizationID:   "id",
+		},
+		Clock: wellnessliving.ClockFunc(func() time.Time {
+			return time.Now().Add(-10 * time.Minute)
+		}),
+	}
+	err := client.Request(context.Background(), http.MethodGet, "/Wl/Business/Data.json", nil, nil, nil)
+	if !errors.Is(err, wellnessliving.ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+	}
+	if offset := client.ClockOffset(); offset != 0 {
+		t.Errorf("Expected no clock offset, got %v", offset)
+	}
+}
@@ -0,0 +1,75 @@
+package wellnessliving
+
+import (
+	"net/http"
+	"time"
+)
+
+// Clock provides the current time.
+//
+// The request signature includes the current time, so this is useful for testing.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is a function that is a Clock.
+type ClockFunc func() time.Time
+
+var _ Clock = ClockFunc(nil)
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// systemClock is the system clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// clockOffsetThreshold is the smallest offset that is worth correcting.
+//
+// The "Date" header only has a resolution of one second, so anything smaller than this is just noise.
+const clockOffsetThreshold = 2 * time.Second
+
+// clock returns the client's clock, without any correction.
+func (c *Client) clock() Clock {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return systemClock{}
+}
+
+// now returns the current time according to the server; that is, the client's clock corrected by the measured offset.
+func (c *Client) now() time.Time {
+	return c.clock().Now().Add(c.ClockOffset())
+}
+
+// ClockOffset returns how far ahead of the client's clock the server's clock is (negative if it is behind).
+//
+// This is measured from the "Date" header of every response, and it is used to correct the time in
+// request signatures.
+func (c *Client) ClockOffset() time.Duration {
+	return time.Duration(c.clockOffset.Load())
+}
+
+// updateClockOffset measures the clock offset from a response's "Date" header.
+//
+// sent and received are the times (according to the client's clock) at which the request was sent and the response was received.
+func (c *Client) updateClockOffset(date string, sent time.Time, received time.Time) {
+	if date == "" {
+		return
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return
+	}
+
+	// Assume that the server generated the response halfway through the round trip.
+	offset := serverTime.Sub(sent.Add(received.Sub(sent) / 2))
+	if offset > -clockOffsetThreshold && offset < clockOffsetThreshold {
+		offset = 0
+	}
+	c.clockOffset.Store(int64(offset))
+}
@@ -6,7 +6,10 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/sirupsen/logrus"
@@ -22,6 +25,10 @@ func main() {
 	var verbose bool
 	var username string
 	var password string
+	var sessionPath string
+	var noSession bool
+	var recordPath string
+	var replayPath string
 	rootCommand := &cobra.Command{
 		Use: "wellnessliving",
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
@@ -29,7 +36,40 @@ func main() {
 				logrus.SetLevel(logrus.DebugLevel)
 			}
 
+			if recordPath != "" && replayPath != "" {
+				logrus.WithContext(ctx).Errorf("Only one of --record and --replay may be given.")
+				os.Exit(1)
+			}
+			if recordPath != "" {
+				client.HTTPClient.Transport = &wellnessliving.Recorder{Path: recordPath, Mode: wellnessliving.RecorderModeRecord}
+			}
+			if replayPath != "" {
+				client.HTTPClient.Transport = &wellnessliving.Recorder{Path: replayPath, Mode: wellnessliving.RecorderModeReplay}
+				// A replayed session would never have been saved.
+				noSession = true
+			}
+
+			if !noSession {
+				if sessionPath == "" {
+					var err error
+					sessionPath, err = wellnessliving.DefaultSessionPath()
+					if err != nil {
+						logrus.WithContext(ctx).Errorf("Could not determine the session path: %v", err)
+						os.Exit(1)
+					}
+				}
+			}
+
 			if username != "" || password != "" {
+				if !noSession {
+					client.SessionStore = &wellnessliving.FileSessionStore{Path: sessionPath}
+				}
+
+				// If a restored session turns out to have expired, then log in again.
+				client.LoginCredentials = wellnessliving.LoginCredentialsFunc(func(ctx context.Context) (string, string, error) {
+					return username, password, nil
+				})
+
 				err := client.Login(ctx, username, password)
 				if err != nil {
 					logrus.WithContext(ctx).Errorf("Could not log in as %q: %v", username, err)
@@ -41,6 +81,10 @@ func main() {
 	rootCommand.PersistentFlags().BoolVar(&verbose, "verbose", false, "Enable verbose logging.")
 	rootCommand.PersistentFlags().StringVar(&username, "username", "", "The WellnessLiving user to login as (if any).")
 	rootCommand.PersistentFlags().StringVar(&password, "password", "", "The WellnessLiving user to login as (if any).")
+	rootCommand.PersistentFlags().StringVar(&sessionPath, "session", "", "The file to save the login session in.  If empty, this is in the user's config directory.")
+	rootCommand.PersistentFlags().BoolVar(&noSession, "no-session", false, "Do not save or reuse the login session.")
+	rootCommand.PersistentFlags().StringVar(&recordPath, "record", "", "Record the requests and responses to this cassette file (with the secrets redacted).")
+	rootCommand.PersistentFlags().StringVar(&replayPath, "replay", "", "Replay the responses from this cassette file instead of making real requests.")
 
 	{
 		var bodyString string
@@ -73,19 +117,12 @@ func main() {
 	}
 
 	{
+		var input wellnessliving.EventListRequest
 		cmd := &cobra.Command{
 			Use:  "list-events [key=value [...]]",
 			Args: cobra.MinimumNArgs(0),
 			Run: func(cmd *cobra.Command, args []string) {
-				values := url.Values{}
-				for _, v := range args {
-					if !strings.Contains(v, "=") {
-						logrus.WithContext(ctx).Errorf("Invalid syntax for variable %q; expected '='.", v)
-						os.Exit(1)
-					}
-					parts := strings.SplitN(v, "=", 2)
-					values.Set(parts[0], parts[1])
-				}
+				values := variablesWithArgs(ctx, input, args)
 
 				var eventListResponse wellnessliving.EventListResponse
 				err := client.Request(ctx, http.MethodGet, "/Wl/Event/EventList.json", values, nil, &eventListResponse)
@@ -96,23 +133,20 @@ func main() {
 				spew.Dump(eventListResponse)
 			},
 		}
+		cmd.Flags().IntVar((*int)(&input.BusinessID), "business", 0, "The business ID.")
+		cmd.Flags().IntVar((*int)(&input.ClassTabID), "class-tab", 0, "The class tab ID.")
+		cmd.Flags().BoolVar(&input.IsTabAll, "all-tabs", false, "List the events from all of the class tabs.")
+		cmd.Flags().IntVar((*int)(&input.UID), "uid", 0, "The user ID.")
 		rootCommand.AddCommand(cmd)
 	}
 
 	{
+		var input wellnessliving.LocationListRequest
 		cmd := &cobra.Command{
 			Use:  "list-locations [key=value [...]]",
 			Args: cobra.MinimumNArgs(0),
 			Run: func(cmd *cobra.Command, args []string) {
-				values := url.Values{}
-				for _, v := range args {
-					if !strings.Contains(v, "=") {
-						logrus.WithContext(ctx).Errorf("Invalid syntax for variable %q; expected '='.", v)
-						os.Exit(1)
-					}
-					parts := strings.SplitN(v, "=", 2)
-					values.Set(parts[0], parts[1])
-				}
+				values := variablesWithArgs(ctx, input, args)
 
 				var locationListResponse wellnessliving.LocationListResponse
 				err := client.Request(ctx, http.MethodGet, "/Wl/Location/List.json", values, nil, &locationListResponse)
@@ -125,23 +159,39 @@ func main() {
 				}
 			},
 		}
+		cmd.Flags().IntVar((*int)(&input.BusinessID), "business", 0, "The business ID.")
+		rootCommand.AddCommand(cmd)
+	}
+
+	{
+		cmd := &cobra.Command{
+			Use:  "get-location <location-id>",
+			Args: cobra.ExactArgs(1),
+			Run: func(cmd *cobra.Command, args []string) {
+				locationID, err := strconv.Atoi(args[0])
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("Invalid location ID %q: %v", args[0], err)
+					os.Exit(1)
+				}
+
+				locationResponse, err := client.GetLocation(ctx, wellnessliving.LocationRequest{LocationID: wellnessliving.Integer(locationID)})
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("Could not perform request: [%T] %v", err, err)
+					os.Exit(1)
+				}
+				spew.Dump(locationResponse)
+			},
+		}
 		rootCommand.AddCommand(cmd)
 	}
 
 	{
+		var input wellnessliving.TabRequest
 		cmd := &cobra.Command{
 			Use:  "list-tabs [key=value [...]]",
 			Args: cobra.MinimumNArgs(0),
 			Run: func(cmd *cobra.Command, args []string) {
-				values := url.Values{}
-				for _, v := range args {
-					if !strings.Contains(v, "=") {
-						logrus.WithContext(ctx).Errorf("Invalid syntax for variable %q; expected '='.", v)
-						os.Exit(1)
-					}
-					parts := strings.SplitN(v, "=", 2)
-					values.Set(parts[0], parts[1])
-				}
+				values := variablesWithArgs(ctx, input, args)
 
 				var tabResponse wellnessliving.TabResponse
 				err := client.Request(ctx, http.MethodGet, "/Wl/Schedule/Tab/Tab.json", values, nil, &tabResponse)
@@ -157,6 +207,229 @@ func main() {
 				}
 			},
 		}
+		cmd.Flags().IntVar((*int)(&input.BusinessID), "business", 0, "The business ID.")
+		cmd.Flags().IntVar((*int)(&input.UID), "uid", 0, "The user ID.")
+		rootCommand.AddCommand(cmd)
+	}
+
+	{
+		var query wellnessliving.ScheduleQuery
+		var locationIDs []int
+		var staffIDs []int
+		var startString string
+		var endString string
+		var virtual bool
+		var inPerson bool
+		cmd := &cobra.Command{
+			Use:  "list-class-sessions",
+			Args: cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				for _, locationID := range locationIDs {
+					query.LocationIDs = append(query.LocationIDs, wellnessliving.Integer(locationID))
+				}
+				for _, staffID := range staffIDs {
+					query.StaffIDs = append(query.StaffIDs, wellnessliving.Integer(staffID))
+				}
+				switch {
+				case virtual && inPerson:
+					logrus.WithContext(ctx).Errorf("Only one of --virtual and --in-person may be given.")
+					os.Exit(1)
+				case virtual:
+					query.Virtual = wellnessliving.VirtualFilterVirtual
+				case inPerson:
+					query.Virtual = wellnessliving.VirtualFilterInPerson
+				}
+
+				var err error
+				query.StartDate = time.Now()
+				if startString != "" {
+					query.StartDate, err = time.Parse("2006-01-02", startString)
+					if err != nil {
+						logrus.WithContext(ctx).Errorf("Invalid start date %q: %v", startString, err)
+						os.Exit(1)
+					}
+				}
+				query.EndDate = query.StartDate.AddDate(0, 0, 6)
+				if endString != "" {
+					query.EndDate, err = time.Parse("2006-01-02", endString)
+					if err != nil {
+						logrus.WithContext(ctx).Errorf("Invalid end date %q: %v", endString, err)
+						os.Exit(1)
+					}
+				}
+
+				sessions, err := client.ListClassSessions(ctx, query)
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("Could not perform request: [%T] %v", err, err)
+					os.Exit(1)
+				}
+				for _, session := range sessions {
+					fmt.Printf("%s %s (%d minutes) class-period-id=%d location-id=%d\n", session.LocalStartTime.Format("2006-01-02 15:04"), session.Title, session.DurationInMinutes, session.ClassPeriodID, session.LocationID)
+					if session.IsVirtual {
+						fmt.Printf("   virtual\n")
+					}
+					if session.IsCancel {
+						fmt.Printf("   cancelled\n")
+					}
+					for _, staffID := range session.Staff {
+						fmt.Printf("   staff-id=%s\n", staffID)
+					}
+				}
+			},
+		}
+		cmd.Flags().IntVar((*int)(&query.BusinessID), "business", 0, "The business ID.")
+		cmd.Flags().IntSliceVar(&locationIDs, "location", nil, "Only list the sessions at this location ID (may be repeated).")
+		cmd.Flags().IntVar((*int)(&query.ClassTabID), "class-tab", 0, "The class tab ID.")
+		cmd.Flags().BoolVar(&query.IsTabAll, "all-tabs", false, "List the sessions from all of the class tabs.")
+		cmd.Flags().StringVar(&startString, "start", "", "The first day to list (YYYY-MM-DD).  If empty, this is today.")
+		cmd.Flags().StringVar(&endString, "end", "", "The last day to list (YYYY-MM-DD).  If empty, this is six days after the start.")
+		cmd.Flags().IntSliceVar(&staffIDs, "staff", nil, "Only list the sessions with this staff ID (may be repeated).")
+		cmd.Flags().BoolVar(&virtual, "virtual", false, "Only list virtual sessions.")
+		cmd.Flags().BoolVar(&inPerson, "in-person", false, "Only list in-person sessions.")
+		cmd.Flags().BoolVar(&query.IncludeCancelled, "include-cancelled", false, "Also list cancelled sessions.")
+		cmd.Flags().IntVar((*int)(&query.UID), "uid", 0, "The user ID.")
+		rootCommand.AddCommand(cmd)
+	}
+
+	{
+		cmd := &cobra.Command{
+			Use:  "attendance <class-period-id> <date-time>",
+			Args: cobra.ExactArgs(2),
+			Run: func(cmd *cobra.Command, args []string) {
+				classPeriodID, err := strconv.Atoi(args[0])
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("Invalid class period ID %q: %v", args[0], err)
+					os.Exit(1)
+				}
+				date, err := time.Parse("2006-01-02 15:04:05", args[1])
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("Invalid date/time %q (expected the session's start time in UTC as \"YYYY-MM-DD HH:MM:SS\"): %v", args[1], err)
+					os.Exit(1)
+				}
+
+				attendanceListResponse, err := client.GetAttendanceList(ctx, wellnessliving.Integer(classPeriodID), date)
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("Could not perform request: [%T] %v", err, err)
+					os.Exit(1)
+				}
+
+				fmt.Printf("%d of %d booked\n", attendanceListResponse.ClientCount, attendanceListResponse.Capacity)
+				writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintf(writer, "LIST\tVISIT\tSTATUS\tNAME\tEMAIL\n")
+				lists := []struct {
+					name   string
+					people []*wellnessliving.AttendanceListPerson
+				}{
+					{"active", attendanceListResponse.ListActive},
+					{"confirm", attendanceListResponse.ListConfirm},
+					{"wait", attendanceListResponse.ListWait},
+				}
+				for _, list := range lists {
+					for _, person := range list.people {
+						status := "booked"
+						switch {
+						case bool(person.IsAttend):
+							status = "attended"
+						case bool(person.IsTruancy):
+							status = "no-show"
+						case bool(person.IsWait):
+							status = "waiting"
+						}
+						fmt.Fprintf(writer, "%s\t%d\t%s\t%s %s\t%s\n", list.name, person.VisitID, status, person.TextFirstName, person.TextLastName, person.EmailAddress)
+					}
+				}
+				writer.Flush()
+			},
+		}
+		rootCommand.AddCommand(cmd)
+	}
+
+	{
+		var businessID int
+		cmd := &cobra.Command{
+			Use:  "list-staff",
+			Args: cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				staff, err := client.ListStaff(ctx, wellnessliving.Integer(businessID))
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("Could not perform request: [%T] %v", err, err)
+					os.Exit(1)
+				}
+				for _, member := range staff {
+					fmt.Printf("id=%d %s\n", member.StaffID, member.NameFull)
+					if member.Position != "" {
+						fmt.Printf("   position=%s\n", member.Position)
+					}
+				}
+			},
+		}
+		cmd.Flags().IntVar(&businessID, "business", 0, "The business ID.")
+		rootCommand.AddCommand(cmd)
+	}
+
+	{
+		var businessIDs []int
+		cmd := &cobra.Command{
+			Use:  "whoami",
+			Args: cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				var businesses []wellnessliving.Integer
+				for _, businessID := range businessIDs {
+					businesses = append(businesses, wellnessliving.Integer(businessID))
+				}
+				currentUser, err := client.CurrentUser(ctx, businesses...)
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("Could not perform request: [%T] %v", err, err)
+					os.Exit(1)
+				}
+				fmt.Printf("uid=%d %s\n", currentUser.UID, currentUser.Login)
+				if !currentUser.Expires.IsZero() {
+					fmt.Printf("   expires=%s\n", currentUser.Expires.Format(time.RFC3339))
+				}
+				for _, businessID := range businesses {
+					info := currentUser.Businesses[businessID]
+					fmt.Printf("   business-id=%d name=%s %s\n", businessID, info.FirstName, info.LastName)
+					for _, memberGroupID := range info.MemberGroups {
+						fmt.Printf("      member-group-id=%d\n", memberGroupID)
+					}
+				}
+			},
+		}
+		cmd.Flags().IntSliceVar(&businessIDs, "business", nil, "A business ID to show the user's information for (may be repeated).")
+		rootCommand.AddCommand(cmd)
+	}
+
+	{
+		cmd := &cobra.Command{
+			Use:  "logout",
+			Args: cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				if client.SessionStore == nil && !noSession {
+					// Nobody logged in for this command, so log out of the saved session (if any).
+					store := &wellnessliving.FileSessionStore{Path: sessionPath}
+					session, err := store.LoadSession(ctx)
+					if err != nil {
+						logrus.WithContext(ctx).Errorf("Could not load the saved session: %v", err)
+						os.Exit(1)
+					}
+					if session != nil {
+						client.URL = session.URL
+						err = client.RestoreSession(session)
+						if err != nil {
+							logrus.WithContext(ctx).Errorf("Could not restore the saved session: %v", err)
+							os.Exit(1)
+						}
+					}
+					client.SessionStore = store
+				}
+
+				err := client.Logout(ctx)
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("Could not log out: [%T] %v", err, err)
+					os.Exit(1)
+				}
+			},
+		}
 		rootCommand.AddCommand(cmd)
 	}
 
@@ -166,3 +439,22 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// variablesWithArgs encodes the input (which has been filled in from the flags) and then sets any
+// "key=value" arguments on top of it, so that any variable can be given even if it has no flag.
+func variablesWithArgs(ctx context.Context, input interface{}, args []string) url.Values {
+	values, err := wellnessliving.EncodeVariables(input)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Could not encode variables: %v", err)
+		os.Exit(1)
+	}
+	for _, v := range args {
+		if !strings.Contains(v, "=") {
+			logrus.WithContext(ctx).Errorf("Invalid syntax for variable %q; expected '='.", v)
+			os.Exit(1)
+		}
+		parts := strings.SplitN(v, "=", 2)
+		values.Set(parts[0], parts[1])
+	}
+	return values
+}
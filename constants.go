@@ -57,6 +57,11 @@ const (
 	CurrencySIDZAR CurrencySID = 7
 )
 
+// DenyReasonSID is the reason that a client cannot book a class session; see ClassSessionClass.DenyReasonID.
+//
+// TODO: Add the constants from the SDK's deny reason enum; none have been confirmed yet.
+type DenyReasonSID int
+
 type ModeSID int
 
 const (
@@ -0,0 +1,157 @@
+package wellnessliving
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials are the API credentials that WellnessLiving issues as part of its API program.
+type Credentials struct {
+	AuthorizationCode string
+	AuthorizationID   string
+}
+
+// complete returns true if both of the values are set.
+func (c Credentials) complete() bool {
+	return c.AuthorizationCode != "" && c.AuthorizationID != ""
+}
+
+// CredentialsProvider provides the API credentials for every request.
+//
+// This is called for every request, so that the credentials may change over time.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// StaticCredentials are credentials that never change.
+type StaticCredentials Credentials
+
+var _ CredentialsProvider = StaticCredentials{}
+
+func (p StaticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	return Credentials(p), nil
+}
+
+// EnvCredentials loads the credentials from environment variables.
+type EnvCredentials struct {
+	CodeVariable string // The variable with the authorization code.  If empty, this is WELLNESSLIVING_AUTHORIZATION_CODE.
+	IDVariable   string // The variable with the authorization ID.  If empty, this is WELLNESSLIVING_AUTHORIZATION_ID.
+}
+
+var _ CredentialsProvider = EnvCredentials{}
+
+func (p EnvCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	codeVariable := p.CodeVariable
+	if codeVariable == "" {
+		codeVariable = "WELLNESSLIVING_AUTHORIZATION_CODE"
+	}
+	idVariable := p.IDVariable
+	if idVariable == "" {
+		idVariable = "WELLNESSLIVING_AUTHORIZATION_ID"
+	}
+	return Credentials{
+		AuthorizationCode: os.Getenv(codeVariable),
+		AuthorizationID:   os.Getenv(idVariable),
+	}, nil
+}
+
+// FileCredentials loads the credentials from files, each of which contains a single value.
+//
+// This works well with a Kubernetes secret that is mounted as a volume.  The files are read again
+// whenever they change, so the credentials can be rotated without a restart.
+type FileCredentials struct {
+	CodePath string // The file with the authorization code.
+	IDPath   string // The file with the authorization ID.
+
+	mutex       sync.Mutex
+	credentials Credentials
+	codeTime    time.Time // The modification time of CodePath when it was last read.
+	idTime      time.Time // The modification time of IDPath when it was last read.
+}
+
+var _ CredentialsProvider = (*FileCredentials)(nil)
+
+func (p *FileCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	err := readCredentialFile(p.CodePath, &p.credentials.AuthorizationCode, &p.codeTime)
+	if err != nil {
+		return Credentials{}, err
+	}
+	err = readCredentialFile(p.IDPath, &p.credentials.AuthorizationID, &p.idTime)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return p.credentials, nil
+}
+
+// readCredentialFile reads the file into value, unless it hasn't changed since modified.
+func readCredentialFile(path string, value *string, modified *time.Time) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("wellnessliving: could not read credentials file: %w", err)
+	}
+	if info.ModTime().Equal(*modified) {
+		return nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("wellnessliving: could not read credentials file: %w", err)
+	}
+	*value = strings.TrimSpace(string(contents))
+	*modified = info.ModTime()
+	return nil
+}
+
+// CredentialsChain tries each provider in order and uses the first complete set of credentials.
+type CredentialsChain []CredentialsProvider
+
+var _ CredentialsProvider = CredentialsChain{}
+
+func (p CredentialsChain) Credentials(ctx context.Context) (Credentials, error) {
+	var errs []error
+	for _, provider := range p {
+		credentials, err := provider.Credentials(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if credentials.complete() {
+			return credentials, nil
+		}
+	}
+	if len(errs) > 0 {
+		return Credentials{}, errors.Join(errs...)
+	}
+	return Credentials{}, errors.New("wellnessliving: no credentials were found")
+}
+
+// credentials returns the API credentials to use for a request.
+//
+// If the client has a CredentialsProvider, then that is used.  Otherwise, AuthorizationCode and
+// AuthorizationID are used, falling back to the environment for any that are not set.
+func (c *Client) credentials(ctx context.Context) (Credentials, error) {
+	if c.Credentials != nil {
+		return c.Credentials.Credentials(ctx)
+	}
+
+	environment, _ := EnvCredentials{}.Credentials(ctx)
+	credentials := Credentials{
+		AuthorizationCode: c.AuthorizationCode,
+		AuthorizationID:   c.AuthorizationID,
+	}
+	if credentials.AuthorizationCode == "" {
+		credentials.AuthorizationCode = environment.AuthorizationCode
+	}
+	if credentials.AuthorizationID == "" {
+		credentials.AuthorizationID = environment.AuthorizationID
+	}
+	return credentials, nil
+}
@@ -0,0 +1,167 @@
+package wellnessliving
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/httperror"
+)
+
+// These are the broad categories of API errors.
+//
+// Use errors.Is to check whether an error returned by the client is in one of these categories.
+var (
+	ErrNotAuthenticated = errors.New("wellnessliving: not authenticated") // The request requires a login, or the login failed.
+	ErrForbidden        = errors.New("wellnessliving: forbidden")         // The user is logged in, but is not allowed to do this; logging in again will not help.
+	ErrSignatureInvalid = errors.New("wellnessliving: signature invalid") // The request signature was rejected; this is commonly due to clock skew.
+	ErrNotFound         = errors.New("wellnessliving: not found")         // The requested object does not exist.
+	ErrValidation       = errors.New("wellnessliving: validation failed") // One or more of the variables were invalid; see APIError.Response.Errors.
+)
+
+// APIError is an error from the WellnessLiving API.
+//
+// This is returned both for HTTP error statuses and for responses whose envelope has a status other than "ok".
+//
+// This unwraps to the appropriate `httperror` value (for HTTP error statuses) and to the ErrorResponse
+// (if the body had one), so errors.As may be used to get at either.
+type APIError struct {
+	Method     string         // The HTTP method of the request.
+	Path       string         // The path of the request.
+	StatusCode int            // The HTTP status code of the response.
+	Response   *ErrorResponse // The error envelope, if the body had one.
+	Body       []byte         // The raw body of the response.
+
+	httpError  error         // The `httperror` value for the status code, if it was an error status.
+	retryAfter time.Duration // This is the delay requested by the "Retry-After" header, if any.
+}
+
+// newAPIError creates a new APIError, parsing the error envelope out of the body if it has one.
+func newAPIError(method string, path string, statusCode int, body []byte) *APIError {
+	e := &APIError{
+		Method:     method,
+		Path:       path,
+		StatusCode: statusCode,
+		Body:       body,
+	}
+	if statusCode >= 400 {
+		e.httpError = httperror.ErrorFromStatus(statusCode)
+	}
+
+	var errorResponse ErrorResponse
+	err := json.Unmarshal(body, &errorResponse)
+	if err == nil && errorResponse.Status != "" {
+		e.Response = &errorResponse
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	message := ""
+	if e.Response != nil {
+		message = e.Response.message()
+	}
+	if message == "" {
+		message = fmt.Sprintf("http status %d (%s)", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("wellnessliving: %s %s: %s", e.Method, e.Path, message)
+}
+
+func (e *APIError) Unwrap() []error {
+	var errs []error
+	if e.httpError != nil {
+		errs = append(errs, e.httpError)
+	}
+	if e.Response != nil {
+		errs = append(errs, e.Response)
+	}
+	return errs
+}
+
+// These are the envelope codes (the status, class, or per-field SID) that are known to put an error
+// into each category.  WellnessLiving does not publish a list of its error codes, so only exact
+// matches count; anything else is categorized by its HTTP status alone.
+//
+// signatureInvalidCodes only has the code that VerifyMiddleware (and so wltest) responds with; the
+// code that the production API uses for a rejected signature has not been confirmed.  There is no
+// list for ErrNotFound at all; it relies on the HTTP status.
+//
+// TODO: Source the signature and not-found codes from the SDK's error enum.
+var (
+	notAuthenticatedCodes = []string{"passport-login-required", "passport-login-wrong"}
+	signatureInvalidCodes = []string{verifyErrorStatus}
+)
+
+// Is reports whether the error is in the given category (such as ErrNotAuthenticated).
+//
+// This checks the HTTP status code and the exact envelope codes that are known for the category.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotAuthenticated:
+		if e.hasCode(signatureInvalidCodes...) {
+			// A bad signature is not fixed by logging in again.
+			return false
+		}
+		if e.StatusCode == http.StatusUnauthorized {
+			return true
+		}
+		return e.hasCode(notAuthenticatedCodes...)
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrSignatureInvalid:
+		return e.hasCode(signatureInvalidCodes...)
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrValidation:
+		if e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity {
+			return true
+		}
+		if e.Response != nil {
+			for _, fieldError := range e.Response.Errors {
+				if fieldError.Field != nil && *fieldError.Field != "" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// codes returns the codes in the envelope: the status, the class, and the per-field SIDs.
+func (e *APIError) codes() []string {
+	if e.Response == nil {
+		return nil
+	}
+	codes := []string{e.Response.Status, e.Response.Class}
+	for _, fieldError := range e.Response.Errors {
+		codes = append(codes, fieldError.SID)
+	}
+	return codes
+}
+
+// hasCode returns true if any of the codes in the envelope is exactly one of the given codes (ignoring case).
+func (e *APIError) hasCode(known ...string) bool {
+	for _, code := range e.codes() {
+		for _, k := range known {
+			if strings.EqualFold(code, k) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mentions returns true if any of the codes in the envelope contain the given word (ignoring case).
+//
+// This is only for checks where a false match is harmless.
+func (e *APIError) mentions(word string) bool {
+	for _, code := range e.codes() {
+		if strings.Contains(strings.ToLower(code), word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package wellnessliving
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	rows := []struct {
+		name             string
+		statusCode       int
+		body             string
+		notAuthenticated bool
+		forbidden        bool
+		signatureInvalid bool
+		notFound         bool
+		validation       bool
+	}{
+		{
+			name:       "ok status with an unknown code",
+			statusCode: http.StatusOK,
+			body:       `{"status":"error","message":"Something went wrong."}`,
+		},
+		{
+			name:             "unauthorized",
+			statusCode:       http.StatusUnauthorized,
+			body:             `{"status":"error"}`,
+			notAuthenticated: true,
+		},
+		{
+			name:       "forbidden without a body",
+			statusCode: http.StatusForbidden,
+			forbidden:  true,
+		},
+		{
+			name:             "forbidden with a login code",
+			statusCode:       http.StatusForbidden,
+			body:             `{"status":"passport-login-required"}`,
+			notAuthenticated: true,
+			forbidden:        true,
+		},
+		{
+			name:             "login required",
+			statusCode:       http.StatusOK,
+			body:             `{"status":"passport-login-required"}`,
+			notAuthenticated: true,
+		},
+		{
+			name:             "signature invalid",
+			statusCode:       http.StatusUnauthorized,
+			body:             `{"status":"signature-invalid","class":"mismatch"}`,
+			signatureInvalid: true,
+		},
+		{
+			name:       "class session code is not a login problem",
+			statusCode: http.StatusOK,
+			body:       `{"status":"class-session-cancelled"}`,
+		},
+		{
+			name:       "author code is not a login problem",
+			statusCode: http.StatusOK,
+			body:       `{"status":"error","class":"author-missing"}`,
+		},
+		{
+			name:       "passport code that is not known",
+			statusCode: http.StatusOK,
+			body:       `{"status":"passport-photo-too-large"}`,
+		},
+		{
+			name:       "signature mentioned in another code",
+			statusCode: http.StatusOK,
+			body:       `{"status":"document-signature-required"}`,
+		},
+		{
+			name:       "not found status",
+			statusCode: http.StatusNotFound,
+			notFound:   true,
+		},
+		{
+			name:       "not found code without a not found status",
+			statusCode: http.StatusOK,
+			body:       `{"status":"resource-not-found"}`,
+		},
+		{
+			name:       "bad request",
+			statusCode: http.StatusBadRequest,
+			body:       `{"status":"error"}`,
+			validation: true,
+		},
+		{
+			name:       "field error",
+			statusCode: http.StatusOK,
+			body:       `{"status":"error","a_error":[{"s_field":"dt_date","sid":"date-invalid","html_message":"The date is invalid."}]}`,
+			validation: true,
+		},
+		{
+			name:             "codes are matched without case",
+			statusCode:       http.StatusOK,
+			body:             `{"status":"Passport-Login-Required"}`,
+			notAuthenticated: true,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.name, func(t *testing.T) {
+			err := error(newAPIError(http.MethodGet, "/Test.json", row.statusCode, []byte(row.body)))
+			checks := []struct {
+				target   error
+				expected bool
+			}{
+				{ErrNotAuthenticated, row.notAuthenticated},
+				{ErrForbidden, row.forbidden},
+				{ErrSignatureInvalid, row.signatureInvalid},
+				{ErrNotFound, row.notFound},
+				{ErrValidation, row.validation},
+			}
+			for _, check := range checks {
+				if actual := errors.Is(err, check.target); actual != check.expected {
+					t.Errorf("errors.Is(%v, %v): expected %t, got %t", err, check.target, check.expected, actual)
+				}
+			}
+		})
+	}
+}
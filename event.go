@@ -0,0 +1,24 @@
+package wellnessliving
+
+import (
+	"context"
+	"net/http"
+)
+
+// EventListRequest is the input for "/Wl/Event/EventList.json".
+type EventListRequest struct {
+	BusinessID Integer `wl:"k_business,omitempty"`  // The business to list events for.
+	ClassTabID Integer `wl:"k_class_tab,omitempty"` // If set, only events in this class tab are listed.
+	IsTabAll   bool    `wl:"is_tab_all,omitempty"`  // If true, list events from all of the class tabs.
+	UID        Integer `wl:"uid,omitempty"`         // If set, the events are listed from the point of view of this user.
+}
+
+// ListEvents lists the events of a business.
+func (c *Client) ListEvents(ctx context.Context, input EventListRequest) (*EventListResponse, error) {
+	var output EventListResponse
+	err := c.Request(ctx, http.MethodGet, "/Wl/Event/EventList.json", input, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
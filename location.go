@@ -0,0 +1,36 @@
+package wellnessliving
+
+import (
+	"context"
+	"net/http"
+)
+
+// LocationListRequest is the input for "/Wl/Location/List.json".
+type LocationListRequest struct {
+	BusinessID Integer `wl:"k_business,omitempty"` // The business to list locations for.
+}
+
+// ListLocations lists the locations of a business.
+func (c *Client) ListLocations(ctx context.Context, input LocationListRequest) (*LocationListResponse, error) {
+	var output LocationListResponse
+	err := c.Request(ctx, http.MethodGet, "/Wl/Location/List.json", input, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// LocationRequest is the input for "/Wl/Location/View/View.json".
+type LocationRequest struct {
+	LocationID Integer `wl:"k_location"` // The location to look up.
+}
+
+// GetLocation returns the details of a single location.
+func (c *Client) GetLocation(ctx context.Context, input LocationRequest) (*LocationResponse, error) {
+	var output LocationResponse
+	err := c.Request(ctx, http.MethodGet, "/Wl/Location/View/View.json", input, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
@@ -0,0 +1,93 @@
+package wellnessliving
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrCaptchaRequired is matched by the error from LoginWithOptions when WellnessLiving requires a captcha.
+var ErrCaptchaRequired = errors.New("wellnessliving: captcha required")
+
+// LoginOptions are the options for LoginWithOptions.
+type LoginOptions struct {
+	Remember         bool   // If true, ask WellnessLiving to "remember me", which gives a long-lived persistent cookie.
+	Captcha          string // The answer to a captcha challenge; see CaptchaRequiredError.
+	NoRegionRedirect bool   // If true, do not switch the client's URL to the region where the account lives.
+}
+
+// CaptchaRequiredError is returned when WellnessLiving requires a captcha before it will log a user in.
+//
+// Solve the challenge and then log in again with LoginOptions.Captcha set.
+type CaptchaRequiredError struct {
+	APIError  *APIError              // The underlying error.
+	Challenge map[string]interface{} // The fields of the error response other than the standard ones; these describe the challenge.
+}
+
+func newCaptchaRequiredError(apiError *APIError) *CaptchaRequiredError {
+	e := &CaptchaRequiredError{
+		APIError:  apiError,
+		Challenge: map[string]interface{}{},
+	}
+
+	var fields map[string]interface{}
+	if json.Unmarshal(apiError.Body, &fields) == nil {
+		for key, value := range fields {
+			switch key {
+			case "a_error", "class", "code", "message", "s_version", "status":
+				continue
+			}
+			e.Challenge[key] = value
+		}
+	}
+	return e
+}
+
+func (e *CaptchaRequiredError) Error() string {
+	return ErrCaptchaRequired.Error() + ": " + e.APIError.Error()
+}
+
+func (e *CaptchaRequiredError) Unwrap() []error {
+	return []error{ErrCaptchaRequired, e.APIError}
+}
+
+// regionURLs are the production API URLs for each region.
+var regionURLs = map[RegionSID]string{
+	RegionSIDUSEast1:      "https://us.wellnessliving.com",
+	RegionSIDAPSoutheast2: "https://au.wellnessliving.com",
+}
+
+// RegionURL returns the production API URL for the region, or "" if the region is not known.
+func RegionURL(region RegionSID) string {
+	return regionURLs[region]
+}
+
+// regionURL returns the URL that the client should switch to for the given region (from NotepadResponse.RegionID).
+//
+// This only returns a URL if the client is using a production URL; a custom URL (such as staging) is
+// never changed.
+func (c *Client) regionURL(regionID *string) string {
+	if regionID == nil {
+		return ""
+	}
+	region, err := strconv.Atoi(*regionID)
+	if err != nil {
+		return ""
+	}
+	if !isRegionURL(c.baseURL()) {
+		return ""
+	}
+	return RegionURL(RegionSID(region))
+}
+
+// isRegionURL returns true if the URL is one of the production API URLs.
+func isRegionURL(u string) bool {
+	u = strings.TrimRight(u, "/")
+	for _, regionURL := range regionURLs {
+		if u == regionURL {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package wellnessliving
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrUnsupportedHash is returned when WellnessLiving asks for a password hash that is not supported.
+var ErrUnsupportedHash = errors.New("wellnessliving: unsupported password hash")
+
+// passwordSalt is joined with the password before it is hashed.
+//
+// This comes from WellnessLiving's login form.
+var passwordSalt = []string{
+	"r",
+	"4S",
+	"zqX",
+	"zqiOK",
+	"TLVS75V",
+	"Ue5aLaIIG75",
+	"uODJYM2JsCX4G",
+	"kt58wZfHHGQkHW4QN",
+	"Lh9Fl5989crMU4E7P6E",
+}
+
+// passwordHashes are the hash functions for each of the hash modes that the Notepad endpoint may return.
+//
+// Only the modes whose construction has been verified are listed; any other mode (such as the ones
+// for legacy accounts) is rejected rather than guessed at.
+//
+// TODO: Add the legacy modes once there are known-answer vectors for them from WellnessLiving's
+// login script; until then, accounts that use them cannot log in with this client.
+var passwordHashes = map[string]func() hash.Hash{
+	"sha3": sha3.New512, // SHA3-512; this is what current accounts use.
+}
+
+// HashPassword hashes a password for the "/Core/Passport/Login/Enter/Enter.json" endpoint.
+//
+// mode and notepad come from "/Core/Passport/Login/Enter/Notepad.json" (NotepadResponse.Hash and
+// NotepadResponse.Notepad, respectively).
+//
+// The construction is:
+//
+//	hex(H(notepad + hex(H(join(salt, password) + password))))
+//
+// If the mode is not supported, then this returns an error that matches ErrUnsupportedHash.
+func HashPassword(mode string, notepad string, password string) (string, error) {
+	newHash, ok := passwordHashes[mode]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedHash, mode)
+	}
+
+	sum := func(input string) string {
+		h := newHash()
+		h.Write([]byte(input))
+		return fmt.Sprintf("%x", h.Sum(nil))
+	}
+	return sum(notepad + sum(strings.Join(passwordSalt, password)+password)), nil
+}
@@ -0,0 +1,39 @@
+package wellnessliving
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	// The expected values were computed independently with Python's hashlib (hashlib.sha3_512).
+	rows := []struct {
+		notepad  string
+		password string
+		expected string
+	}{
+		{"0123456789abcdef", "secret", "f4ecb61e6cc440a0b2f0f8d392ef1ef75f8fb868eb68336ff1e105d7d8f5ee86e61dca2e729e7b3ec605286a4a9f8b8bdfebcf128cabea57096399ca18d03a40"},
+		{"6d5b4739832708be5d3bd56e9f76016d", "correct horse battery staple", "b6196b17559f30857ceaed58adf4e9a820a42b2b79eae03392a99e2f2183fbcbdb5de264cc5e04a7bab8b3b3feef1c84ff3431e020f3789c98f80e80e2d14a76"},
+		{"n", "", "a4668ab0eb4ec5c204410779486ed42ee548ad4677be0356b57b23a4df5817f135eaf7a4c9d1702f59341178aa0287e6dc6c847ef4b3f33de76613d99cf2c887"},
+		{"notepad", "pässwörd", "abb0143c6e1902c5eaf1c181c4f80e9d4a97fbb603eb5e144f5ffde273e012ba84258201ad14fba3459866a7f220085742587b1a51cdb4ab4a0f899048c4ec89"},
+	}
+	for _, row := range rows {
+		actual, err := HashPassword("sha3", row.notepad, row.password)
+		if err != nil {
+			t.Errorf("HashPassword(%q, %q): unexpected error: %v", row.notepad, row.password, err)
+			continue
+		}
+		if actual != row.expected {
+			t.Errorf("HashPassword(%q, %q): expected %s, got %s", row.notepad, row.password, row.expected, actual)
+		}
+	}
+}
+
+func TestHashPasswordUnsupported(t *testing.T) {
+	for _, mode := range []string{"", "md5", "sha1", "sha256", "SHA3"} {
+		_, err := HashPassword(mode, "notepad", "secret")
+		if !errors.Is(err, ErrUnsupportedHash) {
+			t.Errorf("HashPassword(%q): expected ErrUnsupportedHash, got %v", mode, err)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package wellnessliving
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ClientPool manages one authenticated client per business.
+//
+// Every client in the pool shares the same HTTP transport and rate limiter.  Clients are created
+// (and logged in) the first time that they are needed, and they are evicted (and logged out) after
+// they have been idle for IdleTimeout.  A client is not idle while it is checked out; see For.
+//
+// A ClientPool is safe for concurrent use.
+type ClientPool struct {
+	URL               string              // The base URL for every client; see Client.URL.
+	AuthorizationCode string              // The authorization code for every client; see Client.AuthorizationCode.
+	AuthorizationID   string              // The authorization ID for every client; see Client.AuthorizationID.
+	Credentials       CredentialsProvider // If set, this provides the credentials for every client; see Client.Credentials.
+	Transport         http.RoundTripper   // The HTTP transport for every client.  If nil, http.DefaultTransport is used.
+	RateLimiter       *RateLimiter        // If set, this is shared by every client.
+	RetryPolicy       *RetryPolicy        // If set, this is used by every client.
+	Timeout           time.Duration       // The default timeout for every client; see Client.Timeout.  This also bounds the pool's own logins and logouts.
+	IdleTimeout       time.Duration       // If set, clients that have not been used for this long are evicted.
+
+	// LoginCredentials returns the username and password for the given business.
+	//
+	// This is called when a client is first created and whenever its session expires.
+	LoginCredentials func(ctx context.Context, businessID Integer) (username string, password string, err error)
+
+	mutex   sync.Mutex
+	entries map[Integer]*poolEntry
+}
+
+// defaultPoolTimeout bounds the pool's own logins and logouts when the pool does not have a Timeout.
+const defaultPoolTimeout = time.Minute
+
+// poolEntry is a single client in the pool.
+type poolEntry struct {
+	client    *Client
+	lastUsed  time.Time
+	checkouts int           // The number of callers that are using the client; it is not evicted while this is nonzero.
+	ready     chan struct{} // This is closed once the login has finished.
+	err       error         // This is the login error, if any; it is only valid after ready is closed.
+}
+
+// For checks out the client for the given business, logging in if necessary.
+//
+// The client is not evicted (or logged out) while it is checked out.  Call the returned function
+// once the client is no longer needed; it is safe to call it more than once.
+//
+// If another goroutine is already logging in for the business, then this waits for it.  The login
+// is shared by every caller, so it is not cancelled when any one of them gives up; it is bounded
+// by Timeout instead (or by a minute, if Timeout is not set).
+func (p *ClientPool) For(ctx context.Context, businessID Integer) (*Client, func(), error) {
+	if p.LoginCredentials == nil {
+		return nil, nil, errors.New("wellnessliving: the client pool does not have LoginCredentials")
+	}
+
+	now := time.Now()
+	p.mutex.Lock()
+	evicted := p.evictIdle(now)
+	if p.entries == nil {
+		p.entries = map[Integer]*poolEntry{}
+	}
+	entry := p.entries[businessID]
+	if entry != nil {
+		entry.lastUsed = now
+	} else {
+		entry = &poolEntry{
+			client:   p.newClient(businessID),
+			lastUsed: now,
+			ready:    make(chan struct{}),
+		}
+		p.entries[businessID] = entry
+		go p.login(context.WithoutCancel(ctx), entry, businessID)
+	}
+	entry.checkouts++
+	p.mutex.Unlock()
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+
+			entry.checkouts--
+			entry.lastUsed = time.Now()
+		})
+	}
+
+	if len(evicted) > 0 {
+		go p.logout(context.WithoutCancel(ctx), evicted)
+	}
+
+	select {
+	case <-ctx.Done():
+		release()
+		return nil, nil, ctx.Err()
+	case <-entry.ready:
+	}
+	if entry.err != nil {
+		release()
+		return nil, nil, entry.err
+	}
+	return entry.client, release, nil
+}
+
+// Evict removes the client for the given business from the pool (without logging out).
+func (p *ClientPool) Evict(businessID Integer) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.entries, businessID)
+}
+
+// Close logs out every client in the pool and empties it.
+//
+// This logs out the clients that are checked out, too, so it should only be called once nothing is using them.
+func (p *ClientPool) Close(ctx context.Context) error {
+	p.mutex.Lock()
+	entries := p.entries
+	p.entries = nil
+	p.mutex.Unlock()
+
+	var errs []error
+	for businessID, entry := range entries {
+		select {
+		case <-entry.ready:
+		default:
+			// This is still logging in; there's nothing to log out of yet.
+			continue
+		}
+		if entry.err != nil {
+			continue
+		}
+		err := entry.client.Logout(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("business %d: %w", businessID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// evictIdle removes the clients that have been idle for too long and returns them.
+//
+// The caller must hold the mutex, and should log the evicted clients out (without holding it).
+func (p *ClientPool) evictIdle(now time.Time) []*poolEntry {
+	if p.IdleTimeout <= 0 {
+		return nil
+	}
+	var evicted []*poolEntry
+	for businessID, entry := range p.entries {
+		select {
+		case <-entry.ready:
+		default:
+			// This is still logging in, so it is about to be used.
+			continue
+		}
+		if entry.checkouts > 0 {
+			continue
+		}
+		if now.Sub(entry.lastUsed) > p.IdleTimeout {
+			delete(p.entries, businessID)
+			evicted = append(evicted, entry)
+		}
+	}
+	return evicted
+}
+
+// logout logs out the clients of the given entries, so that their sessions don't stay open.
+//
+// Any errors are ignored, since nobody is waiting for them.
+func (p *ClientPool) logout(ctx context.Context, entries []*poolEntry) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	for _, entry := range entries {
+		if entry.err != nil {
+			continue
+		}
+		_ = entry.client.Logout(ctx)
+	}
+}
+
+// newClient creates a new client for the business.
+func (p *ClientPool) newClient(businessID Integer) *Client {
+	client := &Client{
+		URL:               p.URL,
+		AuthorizationCode: p.AuthorizationCode,
+		AuthorizationID:   p.AuthorizationID,
+		Credentials:       p.Credentials,
+		RetryPolicy:       p.RetryPolicy,
+		RateLimiter:       p.RateLimiter,
+		Timeout:           p.Timeout,
+	}
+	client.HTTPClient.Transport = p.Transport
+	client.LoginCredentials = LoginCredentialsFunc(func(ctx context.Context) (string, string, error) {
+		return p.LoginCredentials(ctx, businessID)
+	})
+	return client
+}
+
+// login logs the entry's client in for the business, and then marks the entry as ready.
+//
+// If the login fails, then the entry is removed from the pool so that the next caller tries again.
+func (p *ClientPool) login(ctx context.Context, entry *poolEntry, businessID Integer) {
+	defer close(entry.ready)
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	username, password, err := p.LoginCredentials(ctx, businessID)
+	if err == nil {
+		err = entry.client.Login(ctx, username, password)
+	} else {
+		err = fmt.Errorf("wellnessliving: could not get the credentials for business %d: %w", businessID, err)
+	}
+	if err != nil {
+		entry.err = err
+
+		p.mutex.Lock()
+		if p.entries[businessID] == entry {
+			delete(p.entries, businessID)
+		}
+		p.mutex.Unlock()
+	}
+}
+
+// withTimeout bounds the pool's own logins and logouts, which are not tied to any caller's context.
+func (p *ClientPool) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = defaultPoolTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,175 @@
+package wellnessliving_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/wellnessliving"
+	"github.com/tekkamanendless/wellnessliving/wltest"
+)
+
+const logoutPath = "/Core/Passport/Login/Logout/Logout.json"
+
+// newTestPool returns a pool that talks to the server and logs in as the given user for every business.
+func newTestPool(server *wltest.Server, login string, password string) *wellnessliving.ClientPool {
+	client := server.Client()
+	return &wellnessliving.ClientPool{
+		URL:         server.URL,
+		Credentials: client.Credentials,
+		Transport:   client.HTTPClient.Transport,
+		LoginCredentials: func(ctx context.Context, businessID wellnessliving.Integer) (string, string, error) {
+			return login, password, nil
+		},
+	}
+}
+
+// waitForRequests waits for the server to have seen the given number of requests for the path.
+func waitForRequests(t *testing.T, server *wltest.Server, path string, count int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for server.Requests(path) < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d requests for %s, got %d", count, path, server.Requests(path))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClientPool(t *testing.T) {
+	t.Run("SharedLogin", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		pool := newTestPool(server, "jane@example.com", "secret")
+
+		clients := make([]*wellnessliving.Client, 5)
+		var wg sync.WaitGroup
+		for i := range clients {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				client, release, err := pool.For(context.Background(), 1000)
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+					return
+				}
+				defer release()
+				clients[i] = client
+			}(i)
+		}
+		wg.Wait()
+
+		for _, client := range clients {
+			if client != clients[0] {
+				t.Errorf("Expected every caller to get the same client")
+			}
+		}
+		if count := server.Requests(enterPath); count != 1 {
+			t.Errorf("Expected 1 login, got %d", count)
+		}
+	})
+	t.Run("CheckedOutIsNotEvicted", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		pool := newTestPool(server, "jane@example.com", "secret")
+		pool.IdleTimeout = time.Nanosecond
+
+		client, release, err := pool.For(context.Background(), 1000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+
+		// Checking out another business would evict the first one if it were idle.
+		_, otherRelease, err := pool.For(context.Background(), 2000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		otherRelease()
+		again, againRelease, err := pool.For(context.Background(), 1000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		againRelease()
+		if again != client {
+			t.Errorf("Expected the checked-out client to still be in the pool")
+		}
+		if count := server.Requests(logoutPath); count != 0 {
+			t.Errorf("Expected no logouts, got %d", count)
+		}
+
+		release()
+		release() // This is safe to call more than once.
+		time.Sleep(time.Millisecond)
+
+		_, otherRelease, err = pool.For(context.Background(), 2000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer otherRelease()
+		waitForRequests(t, server, logoutPath, 1)
+
+		again, againRelease, err = pool.For(context.Background(), 1000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer againRelease()
+		if again == client {
+			t.Errorf("Expected the idle client to have been evicted")
+		}
+	})
+	t.Run("LoginFailure", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		pool := newTestPool(server, "jane@example.com", "wrong")
+
+		for i := 0; i < 2; i++ {
+			_, _, err := pool.For(context.Background(), 1000)
+			if !errors.Is(err, wellnessliving.ErrNotAuthenticated) {
+				t.Errorf("Expected ErrNotAuthenticated, got %v", err)
+			}
+		}
+		// A failed login is not kept, so the next caller tries again.
+		if count := server.Requests(enterPath); count != 2 {
+			t.Errorf("Expected 2 logins, got %d", count)
+		}
+	})
+	t.Run("Canceled", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.AddUser("jane@example.com", "secret")
+		pool := newTestPool(server, "jane@example.com", "secret")
+		proceed := make(chan struct{})
+		pool.LoginCredentials = func(ctx context.Context, businessID wellnessliving.Integer) (string, string, error) {
+			<-proceed
+			return "jane@example.com", "secret", nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err := pool.For(ctx, 1000)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		close(proceed)
+
+		// The shared login carries on without the caller that gave up.
+		client, release, err := pool.For(context.Background(), 1000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer release()
+		if client.Session() == nil {
+			t.Errorf("Expected the client to be logged in")
+		}
+		if count := server.Requests(enterPath); count != 1 {
+			t.Errorf("Expected 1 login, got %d", count)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package wellnessliving
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit is the configuration of a token bucket.
+type RateLimit struct {
+	Rate  float64 // The number of requests per second.  If zero, there is no limit.
+	Burst int     // The maximum number of requests that may be made at once.  If less than 1, this is 1.
+}
+
+// RateLimiter is a token-bucket rate limiter for WellnessLiving requests.
+//
+// A single RateLimiter may be shared by any number of clients and goroutines.
+// Each host gets its own bucket, so that (for example) the staging and production APIs are
+// limited independently.
+//
+// The zero value does not limit anything.
+type RateLimiter struct {
+	RateLimit                      // This is the limit for every host that is not in Hosts.
+	Hosts     map[string]RateLimit // This is the limit for specific hosts, such as "us.wellnessliving.com".
+	Clock     Clock                // If set, this is used instead of the system clock.
+
+	mutex   sync.Mutex
+	buckets map[string]*rateBucket
+	sleep   func(ctx context.Context, delay time.Duration) error // If set, this is used instead of sleepContext; this is for testing.
+}
+
+// RateLimiterStats are the statistics for a single host of a RateLimiter.
+type RateLimiterStats struct {
+	Requests int64         // The number of requests that have been allowed.
+	Waited   int64         // The number of requests that had to wait.
+	WaitTime time.Duration // The total time that requests spent waiting.
+	Tokens   float64       // The number of tokens currently available; this is negative if requests are queued.
+}
+
+// rateBucket is the token bucket for a single host.
+type rateBucket struct {
+	limit   RateLimit
+	tokens  float64
+	updated time.Time
+	stats   RateLimiterStats
+}
+
+// refill adds the tokens that have accumulated since the last update.
+func (b *rateBucket) refill(now time.Time) {
+	burst := float64(b.limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	b.tokens += now.Sub(b.updated).Seconds() * b.limit.Rate
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.updated = now
+}
+
+// now returns the current time according to the limiter's clock.
+func (l *RateLimiter) now() time.Time {
+	if l.Clock != nil {
+		return l.Clock.Now()
+	}
+	return time.Now()
+}
+
+// sleepContext waits for the delay to pass or the context to be done, whichever is first.
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// limitFor returns the limit for the given host.
+func (l *RateLimiter) limitFor(host string) RateLimit {
+	if limit, ok := l.Hosts[host]; ok {
+		return limit
+	}
+	return l.RateLimit
+}
+
+// Wait blocks until a request to the given host is allowed or the context is done.
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	limit := l.limitFor(host)
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	now := l.now()
+	l.mutex.Lock()
+	if l.buckets == nil {
+		l.buckets = map[string]*rateBucket{}
+	}
+	bucket := l.buckets[host]
+	if bucket == nil {
+		bucket = &rateBucket{
+			tokens:  float64(max(limit.Burst, 1)),
+			updated: now,
+		}
+		l.buckets[host] = bucket
+	}
+	bucket.limit = limit
+	bucket.refill(now)
+
+	// Take a token now; if there wasn't one, then this reserves the next one.
+	bucket.tokens--
+	var delay time.Duration
+	if bucket.tokens < 0 {
+		delay = time.Duration(-bucket.tokens / limit.Rate * float64(time.Second))
+	}
+	bucket.stats.Requests++
+	if delay > 0 {
+		bucket.stats.Waited++
+		bucket.stats.WaitTime += delay
+	}
+	l.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	sleep := l.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+	err := sleep(ctx, delay)
+	if err != nil {
+		// Give back the reservation.
+		l.mutex.Lock()
+		bucket.tokens++
+		bucket.stats.Requests--
+		bucket.stats.Waited--
+		bucket.stats.WaitTime -= delay
+		l.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Stats returns the current statistics, keyed by host.
+func (l *RateLimiter) Stats() map[string]RateLimiterStats {
+	now := l.now()
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	stats := map[string]RateLimiterStats{}
+	for host, bucket := range l.buckets {
+		bucket.refill(now)
+		s := bucket.stats
+		s.Tokens = bucket.tokens
+		stats[host] = s
+	}
+	return stats
+}
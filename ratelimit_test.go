@@ -0,0 +1,204 @@
+package wellnessliving
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when it is told to.
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// newTestRateLimiter returns a rate limiter whose sleeps advance the fake clock instead of
+// actually sleeping.  The delays that it slept for are recorded.
+func newTestRateLimiter(limit RateLimit) (*RateLimiter, *fakeClock, *[]time.Duration) {
+	clock := &fakeClock{now: time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)}
+	var delays []time.Duration
+	limiter := &RateLimiter{
+		RateLimit: limit,
+		Clock:     clock,
+	}
+	limiter.sleep = func(ctx context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		clock.Advance(delay)
+		return nil
+	}
+	return limiter, clock, &delays
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	limiter, _, delays := newTestRateLimiter(RateLimit{Rate: 2, Burst: 3})
+
+	for i := 0; i < 3; i++ {
+		err := limiter.Wait(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(*delays) != 0 {
+		t.Fatalf("Expected the burst to go through without waiting, but waited %v", *delays)
+	}
+
+	// The bucket is empty, so each request waits for the next token (every half-second).
+	for i := 0; i < 2; i++ {
+		err := limiter.Wait(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	expected := []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}
+	if len(*delays) != len(expected) || (*delays)[0] != expected[0] || (*delays)[1] != expected[1] {
+		t.Errorf("Expected delays of %v, got %v", expected, *delays)
+	}
+}
+
+func TestRateLimiterMinimumBurst(t *testing.T) {
+	limiter, _, delays := newTestRateLimiter(RateLimit{Rate: 1})
+
+	for i := 0; i < 2; i++ {
+		err := limiter.Wait(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	// A burst of zero is treated as one, so only the second request waits.
+	if len(*delays) != 1 || (*delays)[0] != time.Second {
+		t.Errorf("Expected a single delay of 1s, got %v", *delays)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	limiter, clock, delays := newTestRateLimiter(RateLimit{Rate: 1, Burst: 2})
+
+	for i := 0; i < 2; i++ {
+		_ = limiter.Wait(context.Background(), "example.com")
+	}
+	if tokens := limiter.Stats()["example.com"].Tokens; tokens != 0 {
+		t.Fatalf("Expected 0 tokens, got %v", tokens)
+	}
+
+	clock.Advance(1500 * time.Millisecond)
+	if tokens := limiter.Stats()["example.com"].Tokens; tokens != 1.5 {
+		t.Errorf("Expected 1.5 tokens, got %v", tokens)
+	}
+
+	// The refill is capped at the burst.
+	clock.Advance(time.Hour)
+	if tokens := limiter.Stats()["example.com"].Tokens; tokens != 2 {
+		t.Errorf("Expected 2 tokens, got %v", tokens)
+	}
+	for i := 0; i < 2; i++ {
+		_ = limiter.Wait(context.Background(), "example.com")
+	}
+	if len(*delays) != 0 {
+		t.Errorf("Expected no waiting after the refill, but waited %v", *delays)
+	}
+}
+
+func TestRateLimiterCanceled(t *testing.T) {
+	limiter, _, delays := newTestRateLimiter(RateLimit{Rate: 1, Burst: 1})
+
+	err := limiter.Wait(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = limiter.Wait(ctx, "example.com")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(*delays) != 1 || (*delays)[0] != time.Second {
+		t.Errorf("Expected a single delay of 1s, got %v", *delays)
+	}
+
+	// The canceled request gave back its reservation, so it is not in the stats.
+	stats := limiter.Stats()["example.com"]
+	expected := RateLimiterStats{Requests: 1, Waited: 0, WaitTime: 0, Tokens: 0}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+}
+
+func TestRateLimiterHosts(t *testing.T) {
+	limiter, _, delays := newTestRateLimiter(RateLimit{Rate: 1, Burst: 1})
+	limiter.Hosts = map[string]RateLimit{
+		"staging.wellnessliving.com": {Rate: 4, Burst: 1},
+		"unlimited.example.com":      {},
+	}
+
+	for _, host := range []string{"us.wellnessliving.com", "staging.wellnessliving.com", "unlimited.example.com"} {
+		for i := 0; i < 2; i++ {
+			err := limiter.Wait(context.Background(), host)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	}
+	// Each limited host has its own bucket; the unlimited host never waits.
+	expected := []time.Duration{time.Second, 250 * time.Millisecond}
+	if len(*delays) != len(expected) || (*delays)[0] != expected[0] || (*delays)[1] != expected[1] {
+		t.Errorf("Expected delays of %v, got %v", expected, *delays)
+	}
+
+	stats := limiter.Stats()
+	if len(stats) != 2 {
+		t.Errorf("Expected stats for 2 hosts, got %v", stats)
+	}
+	if _, ok := stats["unlimited.example.com"]; ok {
+		t.Errorf("Expected no stats for the unlimited host")
+	}
+}
+
+func TestRateLimiterStats(t *testing.T) {
+	limiter, _, _ := newTestRateLimiter(RateLimit{Rate: 2, Burst: 1})
+
+	if stats := limiter.Stats(); len(stats) != 0 {
+		t.Errorf("Expected no stats, got %v", stats)
+	}
+	for i := 0; i < 3; i++ {
+		_ = limiter.Wait(context.Background(), "example.com")
+	}
+
+	stats := limiter.Stats()["example.com"]
+	expected := RateLimiterStats{Requests: 3, Waited: 2, WaitTime: time.Second, Tokens: 0}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+}
+
+func TestRateLimiterZeroValue(t *testing.T) {
+	var limiter RateLimiter
+	for i := 0; i < 100; i++ {
+		err := limiter.Wait(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if stats := limiter.Stats(); len(stats) != 0 {
+		t.Errorf("Expected no stats, got %v", stats)
+	}
+}
@@ -0,0 +1,59 @@
+package wellnessliving
+
+import (
+	"context"
+)
+
+// LoginCredentialsProvider provides the username and password that a client uses to log in again
+// when its session expires.
+//
+// This exists so that the password does not have to be kept on the client itself.
+type LoginCredentialsProvider interface {
+	LoginCredentials(ctx context.Context) (username string, password string, err error)
+}
+
+// LoginCredentialsFunc is a function that is a LoginCredentialsProvider.
+type LoginCredentialsFunc func(ctx context.Context) (username string, password string, err error)
+
+var _ LoginCredentialsProvider = LoginCredentialsFunc(nil)
+
+func (f LoginCredentialsFunc) LoginCredentials(ctx context.Context) (string, string, error) {
+	return f(ctx)
+}
+
+// loginGeneration returns the current login generation.
+func (c *Client) loginGeneration() uint64 {
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+
+	return c.authGeneration
+}
+
+// relogin logs in again using the client's LoginCredentials.
+//
+// generation is the login generation from before the request that failed.  If the client has
+// logged in since then (because another goroutine got here first), then this does nothing.
+//
+// The existing cookie jar is reused so that concurrent requests never see the jar change.
+func (c *Client) relogin(ctx context.Context, generation uint64) error {
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+
+	if c.authGeneration != generation {
+		return nil
+	}
+
+	username, password, err := c.LoginCredentials.LoginCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	if c.HTTPClient.Jar == nil {
+		_, err := c.emptyJar()
+		if err != nil {
+			return err
+		}
+	}
+	_, err = c.login(ctx, username, password, LoginOptions{})
+	return err
+}
@@ -0,0 +1,372 @@
+package wellnessliving
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// redacted replaces any secret in a cassette.
+const redacted = "REDACTED"
+
+// RecorderMode is what a Recorder does with requests.
+type RecorderMode int
+
+const (
+	RecorderModeRecord RecorderMode = iota // Make the real request and save it to the cassette.
+	RecorderModeReplay                     // Serve the response from the cassette; never make a real request.
+)
+
+// Cassette is a set of recorded requests and responses.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded request.
+type RecordedRequest struct {
+	Method    string      `json:"method"`
+	Resource  string      `json:"resource"`  // The path, such as "/Wl/Location/List.json".
+	Variables url.Values  `json:"variables"` // The query and form variables.
+	Header    http.Header `json:"header"`
+	Body      string      `json:"body,omitempty"` // Only if the body was not a form.
+}
+
+// RecordedResponse is a recorded response.
+type RecordedResponse struct {
+	StatusCode int             `json:"status_code"`
+	Header     http.Header     `json:"header"`
+	Body       json.RawMessage `json:"body,omitempty"`   // If the body was JSON, then this is it.
+	String     string          `json:"string,omitempty"` // If the body was not JSON, then this is it.
+}
+
+// key returns the key that a request is replayed by.
+//
+// This is the method, resource, and variables, which excludes the signature and the time.
+func (r *RecordedRequest) key() string {
+	return r.Method + " " + r.Resource + "?" + r.Variables.Encode() + "\n" + r.Body
+}
+
+// Recorder is an http.RoundTripper that records requests and responses to a cassette file,
+// or replays them from one.
+//
+// Use it as the transport for the client's HTTP client:
+//
+//	client.HTTPClient.Transport = &wellnessliving.Recorder{Path: "cassette.json"}
+//
+// Recorded requests have their "Authorization" and "Cookie" headers, their "Set-Cookie" response
+// headers, and any secret variables (such as the login, notepad, and password) redacted.  Secret
+// variables are also redacted from the top level of JSON response bodies, so replayed responses
+// may contain "REDACTED" in their place (for example, as the login of the current user).
+//
+// When replaying, a request is matched by its method, resource, and variables; the signature and
+// the time are ignored.  Matching interactions are served in the order that they were recorded;
+// once they have all been served, the last one is served again.
+type Recorder struct {
+	Path            string            // The path to the cassette file.
+	Mode            RecorderMode      // What to do with requests.
+	Transport       http.RoundTripper // When recording, this makes the real requests.  If nil, http.DefaultTransport is used.
+	RedactVariables []string          // Extra variables to redact.  The login, notepad, password, and captcha are always redacted.
+
+	mutex    sync.Mutex
+	cassette *Cassette
+	replayed map[*Interaction]bool
+}
+
+var _ http.RoundTripper = (*Recorder)(nil)
+
+// secretVariables are the variables that are always redacted.
+var secretVariables = []string{"s_captcha", "s_login", "s_notepad", "s_password"}
+
+// secretHeaders are the headers that are always redacted.
+var secretHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	recordedRequest, err := r.recordRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Mode == RecorderModeReplay {
+		return r.replay(request, recordedRequest)
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(contents))
+
+	interaction := &Interaction{
+		Request: *recordedRequest,
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     redactHeader(response.Header),
+		},
+	}
+	if json.Valid(contents) {
+		interaction.Response.Body = redactBody(contents, r.secretVariables())
+	} else {
+		interaction.Response.String = string(contents)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	err = r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	err = r.save()
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// replay returns the recorded response for the request.
+func (r *Recorder) replay(request *http.Request, recordedRequest *RecordedRequest) (*http.Response, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	if r.replayed == nil {
+		r.replayed = map[*Interaction]bool{}
+	}
+
+	key := recordedRequest.key()
+	var interaction *Interaction
+	for _, i := range r.cassette.Interactions {
+		if i.Request.key() != key {
+			continue
+		}
+		interaction = i
+		if !r.replayed[i] {
+			break
+		}
+	}
+	if interaction == nil {
+		return nil, fmt.Errorf("wellnessliving: no recorded response for %s %s", recordedRequest.Method, recordedRequest.Resource)
+	}
+	r.replayed[interaction] = true
+
+	contents := []byte(interaction.Response.Body)
+	if interaction.Response.String != "" {
+		contents = []byte(interaction.Response.String)
+	}
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	// The recorded time would throw off the client's clock correction.
+	header.Del("Date")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(contents)),
+		ContentLength: int64(len(contents)),
+		Request:       request,
+	}, nil
+}
+
+// recordRequest returns the redacted version of the request.
+//
+// The request's body is left intact.
+func (r *Recorder) recordRequest(request *http.Request) (*RecordedRequest, error) {
+	recordedRequest := &RecordedRequest{
+		Method:    request.Method,
+		Resource:  request.URL.Path,
+		Variables: url.Values{},
+		Header:    redactHeader(request.Header),
+	}
+	for key, values := range request.URL.Query() {
+		recordedRequest.Variables[key] = append(recordedRequest.Variables[key], values...)
+	}
+
+	if request.Body != nil && request.Body != http.NoBody {
+		contents, err := io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("wellnessliving: could not read request body: %w", err)
+		}
+		request.Body = io.NopCloser(bytes.NewReader(contents))
+
+		mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+		if mediaType == "application/x-www-form-urlencoded" {
+			values, err := url.ParseQuery(string(contents))
+			if err != nil {
+				return nil, fmt.Errorf("wellnessliving: could not parse request body: %w", err)
+			}
+			for key, values := range values {
+				recordedRequest.Variables[key] = append(recordedRequest.Variables[key], values...)
+			}
+		} else {
+			recordedRequest.Body = string(contents)
+		}
+	}
+
+	for _, name := range r.secretVariables() {
+		if values, ok := recordedRequest.Variables[name]; ok {
+			for i := range values {
+				values[i] = redacted
+			}
+		}
+	}
+	return recordedRequest, nil
+}
+
+// secretVariables returns the names of the variables to redact.
+func (r *Recorder) secretVariables() []string {
+	return append(append([]string(nil), secretVariables...), r.RedactVariables...)
+}
+
+// redactBody returns the JSON body with the secret variables at its top level redacted.
+//
+// If there is nothing to redact, the body is returned as-is.
+func redactBody(contents []byte, secrets []string) json.RawMessage {
+	var object map[string]json.RawMessage
+	err := json.Unmarshal(contents, &object)
+	if err != nil {
+		return json.RawMessage(contents)
+	}
+
+	changed := false
+	for _, name := range secrets {
+		if _, ok := object[name]; ok {
+			object[name] = json.RawMessage(`"` + redacted + `"`)
+			changed = true
+		}
+	}
+	if !changed {
+		return json.RawMessage(contents)
+	}
+
+	result, err := json.Marshal(object)
+	if err != nil {
+		return json.RawMessage(contents)
+	}
+	return result
+}
+
+// load loads the cassette file, if it hasn't been loaded yet.
+//
+// A missing file is an empty cassette.
+func (r *Recorder) load() error {
+	if r.cassette != nil {
+		return nil
+	}
+
+	contents, err := os.ReadFile(r.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			r.cassette = &Cassette{}
+			return nil
+		}
+		return fmt.Errorf("wellnessliving: could not read cassette file: %w", err)
+	}
+
+	var cassette Cassette
+	err = json.Unmarshal(contents, &cassette)
+	if err != nil {
+		return fmt.Errorf("wellnessliving: could not parse cassette file: %w", err)
+	}
+	r.cassette = &cassette
+	return nil
+}
+
+// save writes the cassette file.
+//
+// This is done after every interaction so that nothing is lost if the program exits early.
+func (r *Recorder) save() error {
+	contents, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("wellnessliving: could not encode cassette: %w", err)
+	}
+
+	directory := filepath.Dir(r.Path)
+	if directory != "" {
+		err = os.MkdirAll(directory, 0700)
+		if err != nil {
+			return fmt.Errorf("wellnessliving: could not create cassette directory: %w", err)
+		}
+	}
+	err = os.WriteFile(r.Path, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("wellnessliving: could not write cassette file: %w", err)
+	}
+	return nil
+}
+
+// redactHeader returns a copy of the header with the secret headers redacted.
+func redactHeader(header http.Header) http.Header {
+	result := header.Clone()
+	if result == nil {
+		result = http.Header{}
+	}
+	for _, name := range secretHeaders {
+		if values := result.Values(name); len(values) > 0 {
+			redactedValues := make([]string, len(values))
+			for i, value := range values {
+				redactedValues[i] = redactHeaderValue(name, value)
+			}
+			result[http.CanonicalHeaderKey(name)] = redactedValues
+		}
+	}
+	return result
+}
+
+// redactHeaderValue redacts a header value.
+//
+// For cookies, the names (and attributes) are kept so that the recording still shows which cookies were sent.
+func redactHeaderValue(header string, value string) string {
+	switch http.CanonicalHeaderKey(header) {
+	case "Cookie":
+		var parts []string
+		for _, cookie := range strings.Split(value, ";") {
+			name, _, _ := strings.Cut(strings.TrimSpace(cookie), "=")
+			parts = append(parts, name+"="+redacted)
+		}
+		return strings.Join(parts, "; ")
+	case "Set-Cookie":
+		cookie, attributes, _ := strings.Cut(value, ";")
+		name, _, _ := strings.Cut(cookie, "=")
+		if attributes != "" {
+			return name + "=" + redacted + ";" + attributes
+		}
+		return name + "=" + redacted
+	}
+	return redacted
+}
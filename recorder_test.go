@@ -0,0 +1,102 @@
+package wellnessliving_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tekkamanendless/wellnessliving"
+	"github.com/tekkamanendless/wellnessliving/wltest"
+)
+
+func TestRecorder(t *testing.T) {
+	cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+
+	server := wltest.NewServer()
+	defer server.Close()
+	server.AddUser("jane@example.com", "secret")
+
+	{
+		client := server.Client()
+		client.HTTPClient.Transport = &wellnessliving.Recorder{
+			Path:      cassettePath,
+			Mode:      wellnessliving.RecorderModeRecord,
+			Transport: client.HTTPClient.Transport,
+		}
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		_, err = client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	contents, err := os.ReadFile(cassettePath)
+	if err != nil {
+		t.Fatalf("Could not read the cassette: %v", err)
+	}
+	cassette := string(contents)
+	for _, secret := range []string{"jane@example.com", server.AuthorizationID} {
+		if strings.Contains(cassette, secret) {
+			t.Errorf("The cassette contains %q", secret)
+		}
+	}
+	var parsed wellnessliving.Cassette
+	err = json.Unmarshal(contents, &parsed)
+	if err != nil {
+		t.Fatalf("Could not parse the cassette: %v", err)
+	}
+	redactedNames := map[string]bool{}
+	for _, interaction := range parsed.Interactions {
+		for name, values := range interaction.Request.Variables {
+			if len(values) > 0 && values[0] == "REDACTED" {
+				redactedNames[name] = true
+			}
+		}
+		var body map[string]interface{}
+		if json.Unmarshal(interaction.Response.Body, &body) == nil {
+			for name, value := range body {
+				if value == "REDACTED" {
+					redactedNames[name] = true
+				}
+			}
+		}
+	}
+	for _, name := range []string{"s_login", "s_notepad", "s_password"} {
+		if !redactedNames[name] {
+			t.Errorf("The cassette does not have %q redacted", name)
+		}
+	}
+
+	// The cassette can be replayed without the server.
+	server.Close()
+	{
+		client := &wellnessliving.Client{
+			URL: server.URL,
+			Credentials: wellnessliving.StaticCredentials{
+				AuthorizationCode: "code",
+				AuthorizationID:   "id",
+			},
+		}
+		client.HTTPClient.Transport = &wellnessliving.Recorder{
+			Path: cassettePath,
+			Mode: wellnessliving.RecorderModeReplay,
+		}
+		err := client.Login(context.Background(), "jane@example.com", "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		response, err := client.ListLocations(context.Background(), wellnessliving.LocationListRequest{BusinessID: 1000})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(response.LocationMap) == 0 {
+			t.Errorf("Expected some locations")
+		}
+	}
+}
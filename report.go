@@ -0,0 +1,77 @@
+package wellnessliving
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrStopPaging may be returned by a paging callback to stop paging early without an error.
+var ErrStopPaging = errors.New("wellnessliving: stop paging")
+
+// ReportRequest is the input for "/Wl/Report/Data.json".
+type ReportRequest struct {
+	BusinessID    Integer `wl:"k_business"`
+	ReportID      Integer `wl:"id_report"`
+	ReportGroupID Integer `wl:"id_report_group,omitempty"`
+	ReportViewID  Integer `wl:"id_report_view,omitempty"`
+	Date          Date    `wl:"dt_date,omitempty"`    // The date of the report, for reports that need one.
+	IsRefresh     bool    `wl:"is_refresh,omitempty"` // If true, the report is regenerated instead of being served from WellnessLiving's cache.
+	Page          Integer `wl:"i_page"`               // The page to fetch, starting at 0.  The paging helpers manage this.
+}
+
+// ReportPage is a single page of a report from "/Wl/Report/Data.json".
+//
+// Row is the type of each row; this differs for each report.
+type ReportPage[Row any] struct {
+	BaseResponse
+	LogID string `json:"k_log"`
+
+	Data struct {
+		IsMore bool  `json:"is_more"` // This is true if there are more rows to fetch.
+		Rows   []Row `json:"a_row"`
+	} `json:"a_data"`
+}
+
+// EachReportRow fetches every page of a report and calls fn for each row, in order.
+//
+// Paging starts at request.Page and continues until WellnessLiving says that there are no more rows.
+// If fn returns an error, paging stops and that error is returned; return ErrStopPaging to stop without an error.
+// The context is checked before each page is fetched.
+func EachReportRow[Row any](ctx context.Context, c *Client, request ReportRequest, fn func(row Row) error) error {
+	for {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		var page ReportPage[Row]
+		err = c.Request(ctx, http.MethodGet, "/Wl/Report/Data.json", request, nil, &page)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range page.Data.Rows {
+			err = fn(row)
+			if errors.Is(err, ErrStopPaging) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if !page.Data.IsMore || len(page.Data.Rows) == 0 {
+			return nil
+		}
+		request.Page++
+	}
+}
+
+// EachReportData33Row fetches every row of report 33 (the member list) and calls fn for each one.
+//
+// See EachReportRow for details.
+func (c *Client) EachReportData33Row(ctx context.Context, request ReportRequest, fn func(row ReportData33Row) error) error {
+	request.ReportID = 33
+	return EachReportRow(ctx, c, request, fn)
+}
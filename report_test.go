@@ -0,0 +1,170 @@
+package wellnessliving_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tekkamanendless/wellnessliving"
+	"github.com/tekkamanendless/wellnessliving/wltest"
+)
+
+const reportPath = "/Wl/Report/Data.json"
+
+func TestEachReportRow(t *testing.T) {
+	t.Run("AllPages", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+
+		client := server.Client()
+		var uids []wellnessliving.Integer
+		err := client.EachReportData33Row(context.Background(), wellnessliving.ReportRequest{BusinessID: 1000}, func(row wellnessliving.ReportData33Row) error {
+			uids = append(uids, row.UID)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// The first page is full and the second (final) page is short.
+		expected := []wellnessliving.Integer{9001, 9002, 9003, 9004}
+		if !reflect.DeepEqual(uids, expected) {
+			t.Errorf("Expected %v, got %v", expected, uids)
+		}
+		if count := server.Requests(reportPath); count != 2 {
+			t.Errorf("Expected 2 requests, got %d", count)
+		}
+		if request := server.LastRequest(reportPath); request.Form.Get("i_page") != "1" || request.Form.Get("id_report") != "33" {
+			t.Errorf("Expected the last request to be for page 1 of report 33, got %v", request.Form)
+		}
+	})
+	t.Run("StartPage", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+
+		client := server.Client()
+		var uids []wellnessliving.Integer
+		err := client.EachReportData33Row(context.Background(), wellnessliving.ReportRequest{BusinessID: 1000, Page: 1}, func(row wellnessliving.ReportData33Row) error {
+			uids = append(uids, row.UID)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []wellnessliving.Integer{9004}
+		if !reflect.DeepEqual(uids, expected) {
+			t.Errorf("Expected %v, got %v", expected, uids)
+		}
+	})
+	t.Run("EmptyPage", func(t *testing.T) {
+		// A page without rows ends the report, even if it claims that there are more.
+		server := wltest.NewServer()
+		defer server.Close()
+		server.SetFixture(reportPath+"?i_page=1", `{"status":"ok","a_data":{"is_more":true,"a_row":[]}}`)
+
+		client := server.Client()
+		count := 0
+		err := client.EachReportData33Row(context.Background(), wellnessliving.ReportRequest{BusinessID: 1000}, func(row wellnessliving.ReportData33Row) error {
+			count++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if count != 3 {
+			t.Errorf("Expected 3 rows, got %d", count)
+		}
+		if requests := server.Requests(reportPath); requests != 2 {
+			t.Errorf("Expected 2 requests, got %d", requests)
+		}
+	})
+	t.Run("StopPaging", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+
+		client := server.Client()
+		count := 0
+		err := client.EachReportData33Row(context.Background(), wellnessliving.ReportRequest{BusinessID: 1000}, func(row wellnessliving.ReportData33Row) error {
+			count++
+			if count == 2 {
+				return wellnessliving.ErrStopPaging
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("Expected 2 rows, got %d", count)
+		}
+		if requests := server.Requests(reportPath); requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+	})
+	t.Run("CallbackError", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+
+		client := server.Client()
+		failure := errors.New("failure")
+		count := 0
+		err := client.EachReportData33Row(context.Background(), wellnessliving.ReportRequest{BusinessID: 1000}, func(row wellnessliving.ReportData33Row) error {
+			count++
+			if row.UID == 9003 {
+				return failure
+			}
+			return nil
+		})
+		if !errors.Is(err, failure) {
+			t.Errorf("Expected the callback's error, got %v", err)
+		}
+		if count != 3 {
+			t.Errorf("Expected 3 rows, got %d", count)
+		}
+		if requests := server.Requests(reportPath); requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+	})
+	t.Run("RequestError", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+		server.SetFixture(reportPath+"?i_page=1", `{"status":"error","message":"The report failed."}`)
+
+		client := server.Client()
+		count := 0
+		err := client.EachReportData33Row(context.Background(), wellnessliving.ReportRequest{BusinessID: 1000}, func(row wellnessliving.ReportData33Row) error {
+			count++
+			return nil
+		})
+		var apiError *wellnessliving.APIError
+		if !errors.As(err, &apiError) {
+			t.Errorf("Expected an APIError, got %v", err)
+		}
+		if count != 3 {
+			t.Errorf("Expected 3 rows, got %d", count)
+		}
+	})
+	t.Run("Canceled", func(t *testing.T) {
+		server := wltest.NewServer()
+		defer server.Close()
+
+		client := server.Client()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		count := 0
+		err := client.EachReportData33Row(ctx, wellnessliving.ReportRequest{BusinessID: 1000}, func(row wellnessliving.ReportData33Row) error {
+			count++
+			cancel()
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if count != 3 {
+			t.Errorf("Expected 3 rows, got %d", count)
+		}
+		if requests := server.Requests(reportPath); requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+	})
+}
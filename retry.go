@@ -0,0 +1,179 @@
+package wellnessliving
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RetryPolicy controls how a Client retries requests that failed for transient reasons.
+//
+// The following failures are considered transient:
+// * The request could not be performed (for example, a network error).
+// * The response had one of the StatusCodes.
+// * The response did not have a valid WellnessLiving envelope.
+//
+// Requests whose methods are not idempotent (such as POST) are only retried when the server
+// responded with "429 Too Many Requests" (meaning that the request was not processed), unless
+// RetryNonIdempotent is set.
+//
+// Every attempt is signed again, so the Date and Authorization headers are always fresh.
+type RetryPolicy struct {
+	MaxAttempts        int           // The total number of attempts, including the first.  If this is less than 2, requests are not retried.
+	InitialBackoff     time.Duration // The delay before the first retry.  If zero, this defaults to 500ms.
+	MaxBackoff         time.Duration // The maximum delay between attempts.  If zero, this defaults to 30s.
+	StatusCodes        []int         // The HTTP status codes to retry.  If empty, this defaults to 429, 502, 503, and 504.
+	RetryNonIdempotent bool          // If true, requests with non-idempotent methods are retried for every transient failure.
+}
+
+// DefaultRetryPolicy returns a reasonable retry policy.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+	}
+}
+
+// transientError is a failure that may succeed if it is tried again.
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+func (p *RetryPolicy) initialBackoff() time.Duration {
+	if p.InitialBackoff > 0 {
+		return p.InitialBackoff
+	}
+	return 500 * time.Millisecond
+}
+
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff > 0 {
+		return p.MaxBackoff
+	}
+	return 30 * time.Second
+}
+
+func (p *RetryPolicy) retryStatus(statusCode int) bool {
+	statusCodes := p.StatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	}
+	for _, code := range statusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry (starting at 1).
+//
+// This is exponential with "equal jitter": the delay is somewhere between half of and all of the exponential value.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.initialBackoff()
+	for i := 1; i < retry && delay < p.maxBackoff(); i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff() {
+		delay = p.maxBackoff()
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// shouldRetry returns whether or not the error should be retried and the minimum delay before doing so.
+func (p *RetryPolicy) shouldRetry(err error, idempotent bool) (bool, time.Duration) {
+	switch e := err.(type) {
+	case *APIError:
+		if !p.retryStatus(e.StatusCode) {
+			return false, 0
+		}
+		if !idempotent && !p.RetryNonIdempotent && e.StatusCode != http.StatusTooManyRequests {
+			return false, 0
+		}
+		return true, e.retryAfter
+	case *transientError:
+		if !idempotent && !p.RetryNonIdempotent {
+			return false, 0
+		}
+		return true, 0
+	}
+	return false, 0
+}
+
+// retry calls attempt until it succeeds or the client's retry policy says to stop.
+func (c *Client) retry(ctx context.Context, method string, attempt func() error) error {
+	policy := c.RetryPolicy
+	if policy == nil {
+		return attempt()
+	}
+
+	idempotent := isIdempotent(method)
+	for i := 1; ; i++ {
+		err := attempt()
+		if err == nil {
+			return nil
+		}
+		if i >= policy.MaxAttempts {
+			return err
+		}
+		retry, minimumDelay := policy.shouldRetry(err, idempotent)
+		if !retry {
+			return err
+		}
+
+		delay := policy.backoff(i)
+		if minimumDelay > delay {
+			delay = minimumDelay
+		}
+		logrus.WithContext(ctx).Debugf("Attempt %d failed; retrying in %v: %v", i, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isIdempotent returns whether or not the HTTP method is idempotent.
+func isIdempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses the value of a "Retry-After" header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if t.Before(now) {
+			return 0
+		}
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,264 @@
+package wellnessliving
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// scheduleClassListWindow is the default number of days that are requested from "/Wl/Schedule/ClassList/ClassList.json" at a time.
+const scheduleClassListWindow = 7
+
+// scheduleClassViewBatchSize is the number of sessions that are requested from "/Wl/Schedule/ClassView/ClassView.json" at a time.
+//
+// This keeps the query string (which is sent with every request) to a reasonable length.
+const scheduleClassViewBatchSize = 50
+
+// TabRequest is the input for "/Wl/Schedule/Tab/Tab.json".
+type TabRequest struct {
+	BusinessID Integer `wl:"k_business,omitempty"` // The business to list the schedule tabs for.
+	UID        Integer `wl:"uid,omitempty"`        // If set, the tabs are listed from the point of view of this user.
+}
+
+// ListScheduleTabs lists the schedule tabs of a business.
+func (c *Client) ListScheduleTabs(ctx context.Context, input TabRequest) (*TabResponse, error) {
+	var output TabResponse
+	err := c.Request(ctx, http.MethodGet, "/Wl/Schedule/Tab/Tab.json", input, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// ScheduleClassListRequest is the input for "/Wl/Schedule/ClassList/ClassList.json".
+//
+// Most callers should use ListClassSessions instead.
+type ScheduleClassListRequest struct {
+	BusinessID  Integer   `wl:"k_business,omitempty"`
+	LocationIDs []Integer `wl:"a_location,omitempty"`  // If set, only sessions at these locations are listed.
+	ClassTabID  Integer   `wl:"k_class_tab,omitempty"` // If set, only sessions in this class tab are listed.
+	IsTabAll    bool      `wl:"is_tab_all,omitempty"`  // If true, list sessions from all of the class tabs.
+	StartDate   Date      `wl:"dt_date,omitempty"`     // The first day to list.
+	EndDate     Date      `wl:"dt_end,omitempty"`      // The last day to list.
+	UID         Integer   `wl:"uid,omitempty"`         // If set, the sessions are listed from the point of view of this user.
+}
+
+// VirtualFilter filters class sessions by whether or not they are virtual.
+type VirtualFilter int
+
+const (
+	VirtualFilterAll      VirtualFilter = iota // Both virtual and in-person sessions.
+	VirtualFilterVirtual                       // Only virtual sessions.
+	VirtualFilterInPerson                      // Only in-person sessions.
+)
+
+// ScheduleQuery is the input for ListClassSessions.
+type ScheduleQuery struct {
+	BusinessID       Integer       // The business to list the sessions for.
+	LocationIDs      []Integer     // If set, only sessions at these locations are listed.
+	ClassTabID       Integer       // If set, only sessions in this class tab are listed.
+	IsTabAll         bool          // If true, list sessions from all of the class tabs.
+	StartDate        time.Time     // The first day to list.  Only the date is used.
+	EndDate          time.Time     // The last day to list.  Only the date is used.  If zero, this is the start date.
+	StaffIDs         []Integer     // If set, only sessions with at least one of these staff members are listed.
+	Virtual          VirtualFilter // Whether to list virtual sessions, in-person sessions, or both.
+	IncludeCancelled bool          // If true, cancelled sessions are also listed.
+	UID              Integer       // If set, the sessions are listed from the point of view of this user.
+	WindowDays       int           // The number of days to request at a time.  If zero, this is 7.
+}
+
+// ListClassSessions lists the class sessions in a date range.
+//
+// Long ranges are split up into multiple requests; the results are merged and sorted by start time.
+// The location is sent to the API as well as checked here; the staff and virtual filters are only
+// checked here, since the API doesn't have them.
+func (c *Client) ListClassSessions(ctx context.Context, query ScheduleQuery) ([]ScheduleClassSession, error) {
+	if query.StartDate.IsZero() {
+		return nil, fmt.Errorf("wellnessliving: the start date is required")
+	}
+	startDate := truncateToDate(query.StartDate)
+	endDate := startDate
+	if !query.EndDate.IsZero() {
+		endDate = truncateToDate(query.EndDate)
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("wellnessliving: the end date (%s) is before the start date (%s)", endDate.Format(dateFormat), startDate.Format(dateFormat))
+	}
+	windowDays := query.WindowDays
+	if windowDays <= 0 {
+		windowDays = scheduleClassListWindow
+	}
+
+	type sessionKey struct {
+		classPeriodID Integer
+		startTime     int64 // Unix time; time.Time values cannot be compared reliably.
+	}
+	seen := map[sessionKey]bool{}
+
+	var sessions []ScheduleClassSession
+	for windowStart := startDate; !windowStart.After(endDate); windowStart = windowStart.AddDate(0, 0, windowDays) {
+		windowEnd := windowStart.AddDate(0, 0, windowDays-1)
+		if windowEnd.After(endDate) {
+			windowEnd = endDate
+		}
+
+		input := ScheduleClassListRequest{
+			BusinessID:  query.BusinessID,
+			LocationIDs: query.LocationIDs,
+			ClassTabID:  query.ClassTabID,
+			IsTabAll:    query.IsTabAll,
+			StartDate:   Date{Time: windowStart},
+			EndDate:     Date{Time: windowEnd},
+			UID:         query.UID,
+		}
+		var output ScheduleClassListResponse
+		err := c.Request(ctx, http.MethodGet, "/Wl/Schedule/ClassList/ClassList.json", input, nil, &output)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, session := range output.Sessions {
+			if !query.matches(session) {
+				continue
+			}
+			key := sessionKey{classPeriodID: session.ClassPeriodID, startTime: session.StartTime.Unix()}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			sessions = append(sessions, session)
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.Before(sessions[j].StartTime.Time)
+	})
+	return sessions, nil
+}
+
+// matches returns true if the session passes the query's filters.
+func (q *ScheduleQuery) matches(session ScheduleClassSession) bool {
+	if bool(session.IsCancel) && !q.IncludeCancelled {
+		return false
+	}
+	switch q.Virtual {
+	case VirtualFilterVirtual:
+		if !session.IsVirtual {
+			return false
+		}
+	case VirtualFilterInPerson:
+		if session.IsVirtual {
+			return false
+		}
+	}
+	if len(q.LocationIDs) > 0 {
+		found := false
+		for _, locationID := range q.LocationIDs {
+			if session.LocationID == locationID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(q.StaffIDs) > 0 {
+		found := false
+		for _, staffID := range q.StaffIDs {
+			for _, sessionStaffID := range session.Staff {
+				if sessionStaffID == strconv.Itoa(int(staffID)) {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateToDate returns midnight (in UTC) of the time's calendar date.
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// SessionKey identifies a single class session.
+type SessionKey struct {
+	ClassPeriodID Integer  `wl:"k_class_period"`
+	Date          DateTime `wl:"dt_date"` // The start time of the session.
+}
+
+// Normalize returns the key with its date in UTC (to the second).
+//
+// Keys must be normalized before they are compared or used in a map.
+func (k SessionKey) Normalize() SessionKey {
+	k.Date = DateTime{Time: k.Date.UTC().Truncate(time.Second)}
+	return k
+}
+
+// SessionKey returns the normalized key for the session.
+func (s ScheduleClassSession) SessionKey() SessionKey {
+	return SessionKey{ClassPeriodID: s.ClassPeriodID, Date: s.StartTime}.Normalize()
+}
+
+// ScheduleClassViewRequest is the input for "/Wl/Schedule/ClassView/ClassView.json".
+//
+// Most callers should use GetClassSessions instead.
+type ScheduleClassViewRequest struct {
+	Sessions []SessionKey `wl:"a_session_request,omitempty"`
+	UID      Integer      `wl:"uid,omitempty"` // If set, the sessions are viewed from the point of view of this user (for example, "can_book").
+}
+
+// GetClassSessions returns the details of the given class sessions.
+//
+// The sessions are requested in as few batches as possible.  The results are keyed by the
+// normalized session keys; any sessions that WellnessLiving did not return are not present.
+func (c *Client) GetClassSessions(ctx context.Context, keys []SessionKey) (map[SessionKey]*ClassSessionDetail, error) {
+	return c.getClassSessions(ctx, keys, 0)
+}
+
+// getClassSessions returns the details of the given class sessions from the point of view of the given user (if any).
+func (c *Client) getClassSessions(ctx context.Context, keys []SessionKey, uid Integer) (map[SessionKey]*ClassSessionDetail, error) {
+	normalizedKeys := make([]SessionKey, 0, len(keys))
+	seen := map[SessionKey]bool{}
+	for _, key := range keys {
+		key = key.Normalize()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalizedKeys = append(normalizedKeys, key)
+	}
+
+	results := map[SessionKey]*ClassSessionDetail{}
+	for len(normalizedKeys) > 0 {
+		batch := normalizedKeys
+		if len(batch) > scheduleClassViewBatchSize {
+			batch = batch[:scheduleClassViewBatchSize]
+		}
+		normalizedKeys = normalizedKeys[len(batch):]
+
+		input := ScheduleClassViewRequest{
+			Sessions: batch,
+			UID:      uid,
+		}
+		var output ScheduleClassViewResponse
+		err := c.Request(ctx, http.MethodGet, "/Wl/Schedule/ClassView/ClassView.json", input, nil, &output)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range output.SessionResult {
+			result := &output.SessionResult[i]
+			key := SessionKey{ClassPeriodID: result.ClassPeriodID, Date: result.Date}.Normalize()
+			results[key] = result
+		}
+	}
+	return results, nil
+}
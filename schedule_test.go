@@ -0,0 +1,204 @@
+package wellnessliving_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/wellnessliving"
+	"github.com/tekkamanendless/wellnessliving/wltest"
+)
+
+const (
+	classListPath = "/Wl/Schedule/ClassList/ClassList.json"
+	classViewPath = "/Wl/Schedule/ClassView/ClassView.json"
+)
+
+func TestListClassSessions(t *testing.T) {
+	type session struct {
+		classPeriodID int
+		start         string // UTC.
+		local         string
+	}
+	sessions := []session{
+		{1, "2024-03-01 15:00:00", "2024-03-01 10:00:00"},
+		// This is on the 8th in UTC but the 7th locally, so it is returned for the windows on both sides of that boundary.
+		{2, "2024-03-08 02:00:00", "2024-03-07 21:00:00"},
+		{3, "2024-03-14 15:00:00", "2024-03-14 10:00:00"},
+		{4, "2024-03-20 15:00:00", "2024-03-20 10:00:00"},
+		{5, "2024-03-21 15:00:00", "2024-03-21 10:00:00"},
+	}
+
+	// newServer returns a server whose class list has every session whose UTC or local date is in the requested range.
+	// The ranges that were requested are recorded.
+	newServer := func(windows *[]string) *wltest.Server {
+		server := wltest.NewServer()
+		server.Handle(classListPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := r.Form.Get("dt_date")
+			end := r.Form.Get("dt_end")
+			*windows = append(*windows, start+".."+end)
+
+			var results []map[string]interface{}
+			for _, s := range sessions {
+				utcDate := s.start[:10]
+				localDate := s.local[:10]
+				if (utcDate < start || utcDate > end) && (localDate < start || localDate > end) {
+					continue
+				}
+				results = append(results, map[string]interface{}{
+					"dt_date":        s.start,
+					"dtl_date":       s.local,
+					"k_class_period": strconv.Itoa(s.classPeriodID),
+					"s_title":        fmt.Sprintf("Class %d", s.classPeriodID),
+					"is_cancel":      "0",
+					"is_virtual":     false,
+				})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":    "ok",
+				"a_session": results,
+			})
+		}))
+		return server
+	}
+
+	rows := []struct {
+		name       string
+		startDate  time.Time
+		endDate    time.Time
+		windowDays int
+		windows    []string
+		expected   []wellnessliving.Integer
+	}{
+		{
+			name:      "SeveralWindows",
+			startDate: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2024, time.March, 20, 0, 0, 0, 0, time.UTC),
+			windows:   []string{"2024-03-01..2024-03-07", "2024-03-08..2024-03-14", "2024-03-15..2024-03-20"},
+			expected:  []wellnessliving.Integer{1, 2, 3, 4},
+		},
+		{
+			name:       "CustomWindow",
+			startDate:  time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			endDate:    time.Date(2024, time.March, 20, 0, 0, 0, 0, time.UTC),
+			windowDays: 10,
+			windows:    []string{"2024-03-01..2024-03-10", "2024-03-11..2024-03-20"},
+			expected:   []wellnessliving.Integer{1, 2, 3, 4},
+		},
+		{
+			name:      "Boundary",
+			startDate: time.Date(2024, time.March, 7, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2024, time.March, 8, 0, 0, 0, 0, time.UTC),
+			// Both windows return the boundary session, but it is only listed once.
+			windowDays: 1,
+			windows:    []string{"2024-03-07..2024-03-07", "2024-03-08..2024-03-08"},
+			expected:   []wellnessliving.Integer{2},
+		},
+		{
+			name:      "SingleDay",
+			startDate: time.Date(2024, time.March, 14, 18, 30, 0, 0, time.UTC),
+			windows:   []string{"2024-03-14..2024-03-14"},
+			expected:  []wellnessliving.Integer{3},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.name, func(t *testing.T) {
+			var windows []string
+			server := newServer(&windows)
+			defer server.Close()
+
+			client := server.Client()
+			results, err := client.ListClassSessions(context.Background(), wellnessliving.ScheduleQuery{
+				BusinessID: 1000,
+				StartDate:  row.startDate,
+				EndDate:    row.endDate,
+				WindowDays: row.windowDays,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(windows, row.windows) {
+				t.Errorf("Expected windows %v, got %v", row.windows, windows)
+			}
+			var classPeriodIDs []wellnessliving.Integer
+			for _, result := range results {
+				classPeriodIDs = append(classPeriodIDs, result.ClassPeriodID)
+			}
+			if !reflect.DeepEqual(classPeriodIDs, row.expected) {
+				t.Errorf("Expected sessions %v, got %v", row.expected, classPeriodIDs)
+			}
+		})
+	}
+}
+
+func TestGetClassSessions(t *testing.T) {
+	server := wltest.NewServer()
+	defer server.Close()
+
+	// The class view returns every session that it was asked for; the batch sizes are recorded.
+	var batches []int
+	server.Handle(classViewPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var results []map[string]interface{}
+		for i := 0; ; i++ {
+			classPeriodID := r.Form.Get(fmt.Sprintf("a_session_request[%d][k_class_period]", i))
+			if classPeriodID == "" {
+				break
+			}
+			results = append(results, map[string]interface{}{
+				"k_class_period": classPeriodID,
+				"dt_date":        r.Form.Get(fmt.Sprintf("a_session_request[%d][dt_date]", i)),
+				"a_class":        map[string]interface{}{"s_title": "Class " + classPeriodID},
+			})
+		}
+		batches = append(batches, len(results))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":           "ok",
+			"a_session_result": results,
+		})
+	}))
+
+	start := time.Date(2024, time.March, 1, 15, 0, 0, 0, time.UTC)
+	var keys []wellnessliving.SessionKey
+	for i := 1; i <= 120; i++ {
+		keys = append(keys, wellnessliving.SessionKey{
+			ClassPeriodID: wellnessliving.Integer(i),
+			Date:          wellnessliving.DateTime{Time: start.Add(time.Duration(i) * time.Hour)},
+		})
+	}
+	// These are duplicates (the second one is the same time in another time zone), so they are only requested once.
+	keys = append(keys, keys[0])
+	keys = append(keys, wellnessliving.SessionKey{
+		ClassPeriodID: keys[1].ClassPeriodID,
+		Date:          wellnessliving.DateTime{Time: keys[1].Date.In(time.FixedZone("EST", -5*60*60))},
+	})
+
+	client := server.Client()
+	results, err := client.GetClassSessions(context.Background(), keys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []int{50, 50, 20}; !reflect.DeepEqual(batches, expected) {
+		t.Errorf("Expected batches of %v, got %v", expected, batches)
+	}
+	if len(results) != 120 {
+		t.Errorf("Expected 120 results, got %d", len(results))
+	}
+	for _, key := range keys {
+		result := results[key.Normalize()]
+		if result == nil {
+			t.Errorf("Missing the result for %+v", key)
+			continue
+		}
+		if expected := fmt.Sprintf("Class %d", key.ClassPeriodID); result.Class.Title != expected {
+			t.Errorf("Expected %q, got %q", expected, result.Class.Title)
+		}
+	}
+}
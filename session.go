@@ -0,0 +1,372 @@
+package wellnessliving
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Session is a WellnessLiving login session.
+//
+// This is everything needed to make authenticated requests without logging in again.
+type Session struct {
+	URL      string          `json:"url"`      // The base URL that the session belongs to.
+	Username string          `json:"username"` // The user that logged in.
+	Cookies  []SessionCookie `json:"cookies"`  // The cookies for the base URL, including the persistent ("p") and transient ("t") cookies.
+}
+
+// SessionCookie is a cookie that is part of a session.
+type SessionCookie struct {
+	Name    string    `json:"name"`
+	Value   string    `json:"value"`
+	Expires time.Time `json:"expires"` // If zero, the cookie does not have an expiration time.
+}
+
+// Expired returns true if the session can no longer be used.
+//
+// A session is expired if it doesn't have a persistent or transient cookie, or if any of those
+// cookies have expired.  Note that WellnessLiving may still end a session early.
+func (s *Session) Expired(now time.Time) bool {
+	found := false
+	for _, cookie := range s.Cookies {
+		switch cookie.Name {
+		case "p", "t":
+			found = true
+			if !cookie.Expires.IsZero() && !cookie.Expires.After(now) {
+				return true
+			}
+		}
+	}
+	return !found
+}
+
+// SessionStore saves and loads a session so that it survives restarts.
+type SessionStore interface {
+	LoadSession(ctx context.Context) (*Session, error) // This returns nil (without an error) if there is no session.
+	SaveSession(ctx context.Context, session *Session) error
+	DeleteSession(ctx context.Context) error
+}
+
+// MemorySessionStore is a SessionStore that keeps the session in memory.
+type MemorySessionStore struct {
+	mutex   sync.Mutex
+	session *Session
+}
+
+var _ SessionStore = (*MemorySessionStore)(nil)
+
+func (s *MemorySessionStore) LoadSession(ctx context.Context) (*Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.session == nil {
+		return nil, nil
+	}
+	session := *s.session
+	session.Cookies = append([]SessionCookie(nil), s.session.Cookies...)
+	return &session, nil
+}
+
+func (s *MemorySessionStore) SaveSession(ctx context.Context, session *Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	saved := *session
+	saved.Cookies = append([]SessionCookie(nil), session.Cookies...)
+	s.session = &saved
+	return nil
+}
+
+func (s *MemorySessionStore) DeleteSession(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.session = nil
+	return nil
+}
+
+// FileSessionStore is a SessionStore that keeps the session in a JSON file.
+//
+// The file is only readable by the current user, since it contains the session cookies.
+type FileSessionStore struct {
+	Path string // The path to the file.  Any missing directories will be created.
+}
+
+var _ SessionStore = (*FileSessionStore)(nil)
+
+// DefaultSessionPath returns the default path for a FileSessionStore, which is in the user's config directory.
+func DefaultSessionPath() (string, error) {
+	configDirectory, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("wellnessliving: could not find config directory: %w", err)
+	}
+	return filepath.Join(configDirectory, "wellnessliving", "session.json"), nil
+}
+
+func (s *FileSessionStore) LoadSession(ctx context.Context) (*Session, error) {
+	contents, err := os.ReadFile(s.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("wellnessliving: could not read session file: %w", err)
+	}
+
+	var session Session
+	err = json.Unmarshal(contents, &session)
+	if err != nil {
+		return nil, fmt.Errorf("wellnessliving: could not parse session file: %w", err)
+	}
+	return &session, nil
+}
+
+func (s *FileSessionStore) SaveSession(ctx context.Context, session *Session) error {
+	contents, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("wellnessliving: could not encode session: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.Path), 0700)
+	if err != nil {
+		return fmt.Errorf("wellnessliving: could not create session directory: %w", err)
+	}
+	err = os.WriteFile(s.Path, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("wellnessliving: could not write session file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSessionStore) DeleteSession(ctx context.Context) error {
+	err := os.Remove(s.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("wellnessliving: could not delete session file: %w", err)
+	}
+	return nil
+}
+
+// sessionJar is a cookie jar that remembers the expiration times of the cookies that it is given.
+//
+// The standard cookie jar only returns the names and values of its cookies, which isn't enough to save a session.
+//
+// The jar is emptied in place (see reset) rather than replaced, since requests may be using it at the same time.
+type sessionJar struct {
+	mutex   sync.Mutex
+	jar     *cookiejar.Jar
+	cookies map[string]SessionCookie // These are all of the cookies that have been set, keyed by name.
+}
+
+var _ http.CookieJar = (*sessionJar)(nil)
+
+func newSessionJar() (*sessionJar, error) {
+	j := &sessionJar{}
+	err := j.reset()
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// reset removes all of the cookies from the jar.
+func (j *sessionJar) reset() error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return fmt.Errorf("wellnessliving: could not create cookie jar: %w", err)
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.jar = jar
+	j.cookies = map[string]SessionCookie{}
+	return nil
+}
+
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mutex.Lock()
+	jar := j.jar
+	j.mutex.Unlock()
+
+	return jar.Cookies(u)
+}
+
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	now := time.Now()
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.jar.SetCookies(u, cookies)
+	for _, cookie := range cookies {
+		if cookie.MaxAge < 0 || (!cookie.Expires.IsZero() && !cookie.Expires.After(now)) {
+			delete(j.cookies, cookie.Name)
+			continue
+		}
+		sessionCookie := SessionCookie{
+			Name:    cookie.Name,
+			Value:   cookie.Value,
+			Expires: cookie.Expires,
+		}
+		if cookie.MaxAge > 0 {
+			sessionCookie.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+		}
+		j.cookies[cookie.Name] = sessionCookie
+	}
+}
+
+// emptyJar empties the client's cookie jar and returns it.
+//
+// If the client's jar is not a session jar (because this is the first login, or because the caller
+// set their own), then it is replaced with an empty one; after that, it is only ever emptied in
+// place, so that concurrent requests never see the jar change.
+//
+// The caller must hold authMutex.
+func (c *Client) emptyJar() (*sessionJar, error) {
+	if jar, ok := c.HTTPClient.Jar.(*sessionJar); ok {
+		err := jar.reset()
+		if err != nil {
+			return nil, err
+		}
+		return jar, nil
+	}
+
+	jar, err := newSessionJar()
+	if err != nil {
+		return nil, err
+	}
+	c.HTTPClient.Jar = jar
+	return jar, nil
+}
+
+// Session returns the current session.
+//
+// If the HTTP client does not have a cookie jar, then this returns nil.
+func (c *Client) Session() *Session {
+	if c.HTTPClient.Jar == nil {
+		return nil
+	}
+
+	session := &Session{
+		URL:      c.baseURL(),
+		Username: c.username,
+	}
+	if jar, ok := c.HTTPClient.Jar.(*sessionJar); ok {
+		jar.mutex.Lock()
+		for _, cookie := range jar.cookies {
+			session.Cookies = append(session.Cookies, cookie)
+		}
+		jar.mutex.Unlock()
+	} else {
+		// We don't know anything about this jar, so all we can get are the names and values.
+		cookieURL, err := url.Parse(session.URL)
+		if err != nil {
+			return nil
+		}
+		for _, cookie := range c.HTTPClient.Jar.Cookies(cookieURL) {
+			session.Cookies = append(session.Cookies, SessionCookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	return session
+}
+
+// RestoreSession empties the client's cookie jar and then fills it with the session's cookies.
+func (c *Client) RestoreSession(session *Session) error {
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+
+	return c.restoreSession(session)
+}
+
+// restoreSession is RestoreSession.
+//
+// The caller must hold authMutex.
+func (c *Client) restoreSession(session *Session) error {
+	jar, err := c.emptyJar()
+	if err != nil {
+		return err
+	}
+
+	cookieURL, err := url.Parse(session.URL)
+	if err != nil {
+		return fmt.Errorf("wellnessliving: could not parse session URL: %w", err)
+	}
+	var cookies []*http.Cookie
+	for _, cookie := range session.Cookies {
+		cookies = append(cookies, &http.Cookie{
+			Name:    cookie.Name,
+			Value:   cookie.Value,
+			Path:    "/",
+			Expires: cookie.Expires,
+		})
+	}
+	jar.SetCookies(cookieURL, cookies)
+
+	c.username = session.Username
+	return nil
+}
+
+// restoreStoredSession restores the session from the client's SessionStore, if there is a usable one for the user.
+//
+// This returns true if a session was restored.
+func (c *Client) restoreStoredSession(ctx context.Context, username string) (bool, error) {
+	session, err := c.SessionStore.LoadSession(ctx)
+	if err != nil {
+		return false, err
+	}
+	if session == nil {
+		return false, nil
+	}
+	if session.Username != username || session.Expired(time.Now()) {
+		return false, nil
+	}
+	if session.URL != c.baseURL() {
+		// A session from another region is fine, as long as we would have switched to it anyway.
+		if !isRegionURL(session.URL) || !isRegionURL(c.baseURL()) {
+			return false, nil
+		}
+	}
+
+	err = c.restoreSession(session)
+	if err != nil {
+		return false, err
+	}
+	if session.URL != c.baseURL() {
+		c.URL = session.URL
+	}
+	return true, nil
+}
+
+// validateSession returns true if WellnessLiving considers the client's current session to be logged in.
+//
+// This is used to check a restored session once, since WellnessLiving may have ended it early.
+// The caller must hold authMutex.
+func (c *Client) validateSession(ctx context.Context) (bool, error) {
+	var infoResponse PassportInfoResponse
+	err := c.request(ctx, http.MethodGet, "/Core/Passport/Login/Info/Info.json", nil, nil, &infoResponse)
+	if err != nil {
+		if errors.Is(err, ErrNotAuthenticated) {
+			return false, nil
+		}
+		return false, err
+	}
+	return infoResponse.UID != nil && *infoResponse.UID != 0, nil
+}
+
+// saveSession saves the current session to the client's SessionStore, if it has one.
+func (c *Client) saveSession(ctx context.Context) error {
+	if c.SessionStore == nil {
+		return nil
+	}
+	session := c.Session()
+	if session == nil {
+		return nil
+	}
+	return c.SessionStore.SaveSession(ctx, session)
+}
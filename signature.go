@@ -3,7 +3,9 @@ package wellnessliving
 import (
 	"crypto/sha256"
 	"fmt"
+	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -26,21 +28,7 @@ func signatureCompute(signature Signature) string {
 	parts = append(parts, signature.CookiePersistent)
 	parts = append(parts, signature.CookieTransient)
 
-	// TODO: Maybe signature.Variable should be more abstract.
-	//$a_variable=WlModelRequest::signatureArray($a_data['a_variable'])
-	{
-		var keys []string
-		for key := range signature.Variables {
-			keys = append(keys, key)
-		}
-		sort.Strings(keys)
-
-		for _, key := range keys {
-			value := signature.Variables.Get(key)
-			// TODO: If the value is null, use "${key} is null"
-			parts = append(parts, key+"="+value)
-		}
-	}
+	parts = append(parts, signatureArray(signature.Variables)...)
 
 	{
 		var keys []string
@@ -59,3 +47,57 @@ func signatureCompute(signature Signature) string {
 	logrus.Debugf("signatureCompute: parts: [\n%s\n]", input)
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(input)))
 }
+
+// signatureArray returns the sorted "key=value" lines for the variables, the same way that
+// WlModelRequest::signatureArray in the PHP SDK does for the variables that PHP would parse out of
+// the request.
+//
+// That means:
+//   - Nested keys keep their bracket notation: "a_x[k]=1".
+//   - Each "[]" in a key is replaced with the next index for that array, so "a_x[]=1&a_x[]=2"
+//     becomes "a_x[0]=1" and "a_x[1]=2".
+//   - For any other key with multiple values, only the last value counts (since PHP overwrites it).
+func signatureArray(variables url.Values) []string {
+	var keys []string
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	indexes := map[string]int{} // This is the next index for each array that is appended to with "[]".
+	for _, key := range keys {
+		values := variables[key]
+		if len(values) == 0 {
+			continue
+		}
+		if !strings.Contains(key, "[]") {
+			lines = append(lines, key+"="+values[len(values)-1])
+			continue
+		}
+		for _, value := range values {
+			lines = append(lines, signatureArrayKey(key, indexes)+"="+value)
+		}
+	}
+
+	sort.Strings(lines)
+	return lines
+}
+
+// signatureArrayKey replaces each "[]" in the key with the next index for that array.
+func signatureArrayKey(key string, indexes map[string]int) string {
+	var result strings.Builder
+	for {
+		position := strings.Index(key, "[]")
+		if position < 0 {
+			result.WriteString(key)
+			return result.String()
+		}
+		result.WriteString(key[:position])
+		prefix := result.String()
+		index := indexes[prefix]
+		indexes[prefix] = index + 1
+		result.WriteString("[" + strconv.Itoa(index) + "]")
+		key = key[position+2:]
+	}
+}
@@ -0,0 +1,109 @@
+package wellnessliving
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// StaffListRequest is the input for "/Wl/Staff/StaffList.json".
+type StaffListRequest struct {
+	BusinessID Integer `wl:"k_business"`
+}
+
+// ListStaff lists the staff members of a business.
+func (c *Client) ListStaff(ctx context.Context, businessID Integer) (map[Integer]StaffListMember, error) {
+	var output StaffListResponse
+	err := c.Request(ctx, http.MethodGet, "/Wl/Staff/StaffList.json", StaffListRequest{BusinessID: businessID}, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	results := map[Integer]StaffListMember{}
+	for _, member := range output.StaffMap {
+		results[member.StaffID] = member
+	}
+	return results, nil
+}
+
+// StaffViewRequest is the input for "/Wl/Staff/StaffView/StaffView.json".
+type StaffViewRequest struct {
+	StaffIDs []Integer `wl:"a_staff"`
+}
+
+// GetStaff returns the profiles of the given staff members.
+//
+// Any staff members that WellnessLiving did not return are not present.
+func (c *Client) GetStaff(ctx context.Context, staffIDs ...Integer) (map[Integer]StaffProfile, error) {
+	results := map[Integer]StaffProfile{}
+	if len(staffIDs) == 0 {
+		return results, nil
+	}
+
+	var output StaffViewResponse
+	err := c.Request(ctx, http.MethodGet, "/Wl/Staff/StaffView/StaffView.json", StaffViewRequest{StaffIDs: staffIDs}, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range output.ResultList {
+		results[result.Staff.StaffID] = result.Staff
+	}
+	return results, nil
+}
+
+// PhotoURL returns the URL of the staff member's photo, or "" if there isn't one.
+func (p *StaffProfile) PhotoURL() string {
+	photo, ok := p.Photo[strconv.Itoa(int(p.StaffID))]
+	if !ok {
+		// The key should be the staff ID, but use whatever is there.
+		for _, photo = range p.Photo {
+			ok = true
+			break
+		}
+	}
+	if !ok || bool(photo.IsEmpty) {
+		return ""
+	}
+	return photo.URL
+}
+
+// BiographyBody returns the body of the staff member's biography as an HTML fragment.
+//
+// Like the BiographyBody function, this does not sanitize the biography.
+func (p *StaffProfile) BiographyBody() string {
+	if p.Biography == "" {
+		return BiographyBody(p.BiographyHTML)
+	}
+	return BiographyBody(p.Biography)
+}
+
+var (
+	biographyBodyStart = regexp.MustCompile(`(?is)<body\b[^>]*>`)
+	biographyBodyEnd   = regexp.MustCompile(`(?is)</body\s*>`)
+	biographyHead      = regexp.MustCompile(`(?is)<head\b[^>]*>.*?</head\s*>`)
+	biographyDocument  = regexp.MustCompile(`(?is)<!DOCTYPE[^>]*>|</?html\b[^>]*>`)
+)
+
+// BiographyBody turns a staff biography (which is a whole HTML document) into an HTML fragment by
+// keeping only the contents of its body.  A biography that is already a fragment is returned with
+// any document-level tags removed.
+//
+// This is not a sanitizer and it is not a security boundary: scripts, event handler attributes,
+// "javascript:" links, iframes, and so on are all kept.  The biography is written by the business,
+// so pass the result through an HTML sanitizer before embedding it in a page unless you trust it.
+func BiographyBody(document string) string {
+	fragment := document
+	if location := biographyBodyStart.FindStringIndex(fragment); location != nil {
+		fragment = fragment[location[1]:]
+		if location := biographyBodyEnd.FindStringIndex(fragment); location != nil {
+			fragment = fragment[:location[0]]
+		}
+	} else {
+		fragment = biographyHead.ReplaceAllString(fragment, "")
+		fragment = biographyDocument.ReplaceAllString(fragment, "")
+	}
+	return strings.TrimSpace(fragment)
+}
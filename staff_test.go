@@ -0,0 +1,88 @@
+package wellnessliving
+
+import (
+	"testing"
+)
+
+func TestBiographyBody(t *testing.T) {
+	rows := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Empty",
+			input:    "",
+			expected: "",
+		},
+		{
+			name:     "Document",
+			input:    "<!DOCTYPE html><html><head><title>Bio</title><style>p { color: red; }</style></head><body class=\"x\">\n<p>Jane teaches yoga.</p>\n</body></html>",
+			expected: "<p>Jane teaches yoga.</p>",
+		},
+		{
+			name:     "DocumentUppercase",
+			input:    "<!doctype html><HTML><HEAD></HEAD><BODY><p>Jane</p></BODY></HTML>",
+			expected: "<p>Jane</p>",
+		},
+		{
+			name:     "BodyWithoutEnd",
+			input:    "<html><body><p>Jane</p>",
+			expected: "<p>Jane</p>",
+		},
+		{
+			name:     "DocumentWithoutBody",
+			input:    "<!DOCTYPE html><html><head><title>Bio</title></head><p>Jane</p></html>",
+			expected: "<p>Jane</p>",
+		},
+		{
+			name:     "Fragment",
+			input:    "  <p>Jane <b>teaches</b> yoga.</p>  ",
+			expected: "<p>Jane <b>teaches</b> yoga.</p>",
+		},
+		// BiographyBody is not a sanitizer; unsafe markup is kept as-is.
+		{
+			name:     "Script",
+			input:    "<html><body><p>Jane</p><script>alert(1)</script></body></html>",
+			expected: "<p>Jane</p><script>alert(1)</script>",
+		},
+		{
+			name:     "EventHandler",
+			input:    "<img/onerror=alert(1) src=x>",
+			expected: "<img/onerror=alert(1) src=x>",
+		},
+		{
+			name:     "JavaScriptLink",
+			input:    `<a href="javascript:alert(1)">Jane</a>`,
+			expected: `<a href="javascript:alert(1)">Jane</a>`,
+		},
+		{
+			name:     "IFrame",
+			input:    `<body><iframe src="https://example.com/"></iframe></body>`,
+			expected: `<iframe src="https://example.com/"></iframe>`,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.name, func(t *testing.T) {
+			actual := BiographyBody(row.input)
+			if actual != row.expected {
+				t.Errorf("Expected %q, got %q", row.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStaffProfileBiographyBody(t *testing.T) {
+	profile := StaffProfile{
+		BiographyHTML: "<p>Fragment</p>",
+		Biography:     "<!DOCTYPE html><html><body><p>Document</p></body></html>",
+	}
+	if actual := profile.BiographyBody(); actual != "<p>Document</p>" {
+		t.Errorf("Expected the document's body, got %q", actual)
+	}
+
+	profile.Biography = ""
+	if actual := profile.BiographyBody(); actual != "<p>Fragment</p>" {
+		t.Errorf("Expected the fragment, got %q", actual)
+	}
+}
@@ -1,15 +1,47 @@
 package wellnessliving
 
 import (
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
 )
 
+// These are the formats that WellnessLiving uses for dates and date/times.
+const (
+	dateFormat        = "2006-01-02"
+	dateTimeFormat    = "2006-01-02 15:04:05"
+	dateZeroValue     = "0000-00-00"
+	dateTimeZeroValue = "0000-00-00 00:00:00"
+	currencyDecimals  = 2
+)
+
+var (
+	_ encoding.TextMarshaler   = Bool(false)
+	_ encoding.TextUnmarshaler = (*Bool)(nil)
+	_ encoding.TextMarshaler   = Date{}
+	_ encoding.TextUnmarshaler = (*Date)(nil)
+	_ encoding.TextMarshaler   = DateTime{}
+	_ encoding.TextUnmarshaler = (*DateTime)(nil)
+	_ encoding.TextMarshaler   = Currency(0)
+	_ encoding.TextUnmarshaler = (*Currency)(nil)
+	_ encoding.TextMarshaler   = Float(0)
+	_ encoding.TextUnmarshaler = (*Float)(nil)
+	_ encoding.TextMarshaler   = Integer(0)
+	_ encoding.TextUnmarshaler = (*Integer)(nil)
+)
+
 // Bool is a boolean, which could be represented as a bool, an integer, or a string.
+//
+// This is marshaled to JSON as a bool and to text as "1" or "0", regardless of how it was
+// represented when it was unmarshaled; for example, "1" is marshaled back to JSON as true.
 type Bool bool
 
+func (d Bool) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bool(d))
+}
+
 func (d *Bool) UnmarshalJSON(contents []byte) error {
 	{
 		var v bool
@@ -31,36 +63,72 @@ func (d *Bool) UnmarshalJSON(contents []byte) error {
 		var v string
 		err := json.Unmarshal(contents, &v)
 		if err == nil {
-			if v == "" {
-				return nil
-			}
-			f, err := strconv.ParseBool(v)
-			if err != nil {
-				return err
-			}
-			*d = Bool(f)
-			return nil
+			return d.UnmarshalText([]byte(v))
 		}
 	}
 	return fmt.Errorf("bool: could not parse: %q", contents)
 }
 
+func (d Bool) MarshalText() ([]byte, error) {
+	if d {
+		return []byte("1"), nil
+	}
+	return []byte("0"), nil
+}
+
+func (d *Bool) UnmarshalText(contents []byte) error {
+	if len(contents) == 0 {
+		return nil
+	}
+	f, err := strconv.ParseBool(string(contents))
+	if err != nil {
+		return err
+	}
+	*d = Bool(f)
+	return nil
+}
+
 // Date is a specific date.
+//
+// This is marshaled as "2006-01-02".  The zero value is marshaled to JSON as "0000-00-00" and to text as "".
+//
+// Both "" and "0000-00-00" are unmarshaled as the zero value, so a JSON "" does not survive a round
+// trip: it is marshaled back as "0000-00-00".
 type Date struct {
 	time.Time
 }
 
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return json.Marshal(dateZeroValue)
+	}
+	return json.Marshal(d.Format(dateFormat))
+}
+
 func (d *Date) UnmarshalJSON(contents []byte) error {
 	var v string
 	err := json.Unmarshal(contents, &v)
 	if err != nil {
 		return fmt.Errorf("date: could not unmarshal string: %w", err)
 	}
+	return d.UnmarshalText([]byte(v))
+}
 
+func (d Date) MarshalText() ([]byte, error) {
+	if d.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(d.Format(dateFormat)), nil
+}
+
+func (d *Date) UnmarshalText(contents []byte) error {
+	v := string(contents)
 	if v == "" {
+		d.Time = time.Time{}
 		return nil
 	}
-	if v == "0000-00-00" {
+	if v == dateZeroValue {
+		d.Time = time.Time{}
 		return nil
 	}
 
@@ -69,7 +137,7 @@ func (d *Date) UnmarshalJSON(contents []byte) error {
 		return fmt.Errorf("date: could not load location: %w", err)
 	}
 
-	d.Time, err = time.ParseInLocation("2006-01-02", v, location)
+	d.Time, err = time.ParseInLocation(dateFormat, v, location)
 	if err != nil {
 		return fmt.Errorf("date: could not parse string: %w", err)
 	}
@@ -77,21 +145,47 @@ func (d *Date) UnmarshalJSON(contents []byte) error {
 }
 
 // DateTime is a specific date/time.
+//
+// This is marshaled as "2006-01-02 15:04:05" in UTC.  The zero value is marshaled to JSON as
+// "0000-00-00 00:00:00" and to text as "".
+//
+// Both "" and "0000-00-00 00:00:00" are unmarshaled as the zero value, so a JSON "" does not survive
+// a round trip: it is marshaled back as "0000-00-00 00:00:00".
 type DateTime struct {
 	time.Time
 }
 
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return json.Marshal(dateTimeZeroValue)
+	}
+	return json.Marshal(d.UTC().Format(dateTimeFormat))
+}
+
 func (d *DateTime) UnmarshalJSON(contents []byte) error {
 	var v string
 	err := json.Unmarshal(contents, &v)
 	if err != nil {
 		return fmt.Errorf("datetime: could not unmarshal string: %w", err)
 	}
+	return d.UnmarshalText([]byte(v))
+}
+
+func (d DateTime) MarshalText() ([]byte, error) {
+	if d.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(d.UTC().Format(dateTimeFormat)), nil
+}
 
+func (d *DateTime) UnmarshalText(contents []byte) error {
+	v := string(contents)
 	if v == "" {
+		d.Time = time.Time{}
 		return nil
 	}
-	if v == "0000-00-00 00:00:00" {
+	if v == dateTimeZeroValue {
+		d.Time = time.Time{}
 		return nil
 	}
 
@@ -100,7 +194,7 @@ func (d *DateTime) UnmarshalJSON(contents []byte) error {
 		return fmt.Errorf("datetime: could not load location: %w", err)
 	}
 
-	d.Time, err = time.ParseInLocation("2006-01-02 15:04:05", v, location)
+	d.Time, err = time.ParseInLocation(dateTimeFormat, v, location)
 	if err != nil {
 		return fmt.Errorf("datetime: could not parse string: %w", err)
 	}
@@ -108,20 +202,38 @@ func (d *DateTime) UnmarshalJSON(contents []byte) error {
 }
 
 // Currency is an amount of money.
+//
+// This is marshaled as a string with two decimal places, such as "12.50".  Any more precision than
+// that is rounded away, so "12.345" is marshaled back as "12.35".
 type Currency float64
 
+func (d Currency) MarshalJSON() ([]byte, error) {
+	text, err := d.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
 func (d *Currency) UnmarshalJSON(contents []byte) error {
 	var v string
 	err := json.Unmarshal(contents, &v)
 	if err != nil {
 		return fmt.Errorf("currency: could not unmarshal string: %w", err)
 	}
+	return d.UnmarshalText([]byte(v))
+}
 
-	if v == "" {
+func (d Currency) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(d), 'f', currencyDecimals, 64)), nil
+}
+
+func (d *Currency) UnmarshalText(contents []byte) error {
+	if len(contents) == 0 {
 		return nil
 	}
 
-	f, err := strconv.ParseFloat(v, 64)
+	f, err := strconv.ParseFloat(string(contents), 64)
 	if err != nil {
 		return fmt.Errorf("currency: could not parse string: %w", err)
 	}
@@ -130,8 +242,14 @@ func (d *Currency) UnmarshalJSON(contents []byte) error {
 }
 
 // Float is an amount of money.
+//
+// This is marshaled to JSON as a number.
 type Float float64
 
+func (d Float) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(d))
+}
+
 func (d *Float) UnmarshalJSON(contents []byte) error {
 	{
 		var v float64
@@ -158,9 +276,32 @@ func (d *Float) UnmarshalJSON(contents []byte) error {
 	return fmt.Errorf("float: could not parse: %q", contents)
 }
 
+func (d Float) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(d), 'f', -1, 64)), nil
+}
+
+func (d *Float) UnmarshalText(contents []byte) error {
+	if len(contents) == 0 {
+		return nil
+	}
+	f, err := strconv.ParseFloat(string(contents), 64)
+	if err != nil {
+		return fmt.Errorf("float: could not parse: %w", err)
+	}
+	*d = Float(f)
+	return nil
+}
+
 // Integer is an integer, which could be represented as an integer or a string.
+//
+// This is marshaled to JSON as a number, regardless of how it was represented when it was
+// unmarshaled; for example, "5" is marshaled back to JSON as 5.
 type Integer int
 
+func (d Integer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(d))
+}
+
 func (d *Integer) UnmarshalJSON(contents []byte) error {
 	{
 		var v int
@@ -174,16 +315,24 @@ func (d *Integer) UnmarshalJSON(contents []byte) error {
 		var v string
 		err := json.Unmarshal(contents, &v)
 		if err == nil {
-			if v == "" {
-				return nil
-			}
-			f, err := strconv.ParseInt(v, 10, 64)
-			if err != nil {
-				return err
-			}
-			*d = Integer(f)
-			return nil
+			return d.UnmarshalText([]byte(v))
 		}
 	}
 	return fmt.Errorf("integer: could not parse: %q", contents)
 }
+
+func (d Integer) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(int(d))), nil
+}
+
+func (d *Integer) UnmarshalText(contents []byte) error {
+	if len(contents) == 0 {
+		return nil
+	}
+	f, err := strconv.ParseInt(string(contents), 10, 64)
+	if err != nil {
+		return err
+	}
+	*d = Integer(f)
+	return nil
+}
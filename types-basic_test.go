@@ -0,0 +1,92 @@
+package wellnessliving
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// roundTrip unmarshals the JSON into the target, then marshals the target again.
+func roundTrip(t *testing.T, target interface{}, input string) string {
+	t.Helper()
+
+	err := json.Unmarshal([]byte(input), target)
+	if err != nil {
+		t.Fatalf("Could not unmarshal %s: %v", input, err)
+	}
+	output, err := json.Marshal(target)
+	if err != nil {
+		t.Fatalf("Could not marshal %s: %v", input, err)
+	}
+	return string(output)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	rows := []struct {
+		name     string
+		target   func() interface{}
+		input    string
+		expected string
+	}{
+		{"Date", func() interface{} { return new(Date) }, `"2024-03-01"`, `"2024-03-01"`},
+		{"DateZero", func() interface{} { return new(Date) }, `"0000-00-00"`, `"0000-00-00"`},
+		{"DateEmpty", func() interface{} { return new(Date) }, `""`, `"0000-00-00"`},
+		{"DateTime", func() interface{} { return new(DateTime) }, `"2024-03-01 10:30:00"`, `"2024-03-01 10:30:00"`},
+		{"DateTimeZero", func() interface{} { return new(DateTime) }, `"0000-00-00 00:00:00"`, `"0000-00-00 00:00:00"`},
+		{"DateTimeEmpty", func() interface{} { return new(DateTime) }, `""`, `"0000-00-00 00:00:00"`},
+		{"BoolTrue", func() interface{} { return new(Bool) }, `true`, `true`},
+		{"BoolFalse", func() interface{} { return new(Bool) }, `false`, `false`},
+		{"BoolString", func() interface{} { return new(Bool) }, `"1"`, `true`},
+		{"BoolInteger", func() interface{} { return new(Bool) }, `0`, `false`},
+		{"Currency", func() interface{} { return new(Currency) }, `"12.50"`, `"12.50"`},
+		{"CurrencyRounded", func() interface{} { return new(Currency) }, `"12.345"`, `"12.35"`},
+		{"Float", func() interface{} { return new(Float) }, `1.25`, `1.25`},
+		{"FloatString", func() interface{} { return new(Float) }, `"1.25"`, `1.25`},
+		{"Integer", func() interface{} { return new(Integer) }, `5`, `5`},
+		{"IntegerString", func() interface{} { return new(Integer) }, `"5"`, `5`},
+	}
+	for _, row := range rows {
+		t.Run(row.name, func(t *testing.T) {
+			actual := roundTrip(t, row.target(), row.input)
+			if actual != row.expected {
+				t.Errorf("Expected %s, got %s", row.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	rows := []struct {
+		name     string
+		input    string
+		expected string
+		target   interface {
+			MarshalText() ([]byte, error)
+			UnmarshalText([]byte) error
+		}
+	}{
+		{"Date", "2024-03-01", "2024-03-01", new(Date)},
+		{"DateEmpty", "", "", new(Date)},
+		{"DateZero", "0000-00-00", "", new(Date)},
+		{"DateTime", "2024-03-01 10:30:00", "2024-03-01 10:30:00", new(DateTime)},
+		{"DateTimeEmpty", "", "", new(DateTime)},
+		{"Bool", "1", "1", new(Bool)},
+		{"BoolFalse", "false", "0", new(Bool)},
+		{"Currency", "7", "7.00", new(Currency)},
+		{"Integer", "42", "42", new(Integer)},
+	}
+	for _, row := range rows {
+		t.Run(row.name, func(t *testing.T) {
+			err := row.target.UnmarshalText([]byte(row.input))
+			if err != nil {
+				t.Fatalf("Could not unmarshal %q: %v", row.input, err)
+			}
+			output, err := row.target.MarshalText()
+			if err != nil {
+				t.Fatalf("Could not marshal %q: %v", row.input, err)
+			}
+			if string(output) != row.expected {
+				t.Errorf("Expected %q, got %q", row.expected, output)
+			}
+		})
+	}
+}
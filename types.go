@@ -2,6 +2,7 @@ package wellnessliving
 
 import (
 	"fmt"
+	"strings"
 )
 
 // BaseResponse is the base of all responses.
@@ -30,7 +31,27 @@ type Error struct {
 }
 
 func (r *ErrorResponse) Error() string {
-	return fmt.Sprintf("%+v", *r)
+	message := r.message()
+	if message == "" {
+		return fmt.Sprintf("wellnessliving: %s", r.Status)
+	}
+	return fmt.Sprintf("wellnessliving: %s: %s", r.Status, message)
+}
+
+// message returns the most useful message in the response.
+func (r *ErrorResponse) message() string {
+	if r.Message != "" {
+		return r.Message
+	}
+	var messages []string
+	for _, e := range r.Errors {
+		if e.Field != nil && *e.Field != "" {
+			messages = append(messages, *e.Field+": "+e.Message)
+		} else {
+			messages = append(messages, e.Message)
+		}
+	}
+	return strings.Join(messages, "; ")
 }
 
 // NotepadResponse is the response from "/Core/Passport/Login/Enter/Notepad.json".
@@ -42,6 +63,14 @@ type NotepadResponse struct {
 	Notepad  string  `json:"s_notepad"`
 }
 
+// PassportInfoResponse is the response from "/Core/Passport/Login/Info/Info.json".
+type PassportInfoResponse struct {
+	BaseResponse
+
+	UID   *Integer `json:"uid"` // This is null if nobody is logged in.
+	Login string   `json:"s_login"`
+}
+
 // EnterResponse is the response from "/Core/Passport/Login/Enter/Enter.json".
 type EnterResponse struct {
 	URLRedirect string `json:"url_redirect"`
@@ -141,6 +170,7 @@ type EventSchedule struct {
 	TimeText      string             `json:"text_time"`
 }
 
+// StaffMember is a staff member in EventSchedule.
 type StaffMember struct {
 	StaffMemberID Integer `json:"k_staff_member"`
 	BusinessRole  string  `json:"text_business_role"`
@@ -240,84 +270,120 @@ type ScheduleClassSession struct {
 type ScheduleClassViewResponse struct {
 	BaseResponse
 
-	// TODO: "a_asset"
-	// TODO: "a_class"
-	// TODO: "a_location"
-	SessionResult []struct {
-		// TODO: "a_asset"
-		Class struct {
-			// TODO: "a_class_tab"
-			Image struct {
-				Height  Integer `json:"i_height"`
-				Width   Integer `json:"i_width"`
-				IsEmpty Bool    `json:"is_empty"`
-				IsOwn   Bool    `json:"is_own"`
-				URL     string  `json:"https://d12lnanyhdwsnh.cloudfront.net/1/kXI.png"`
-			} `json:"a_image"`
-			// TODO: "a_search_tag"
-			// TODO: "a_tag"
-			CanBook           Bool     `json:"can_book"`
-			GlobalDate        DateTime `json:"dt_date_global"`
-			LocalDate         DateTime `json:"dt_date_local"`
-			HTMLDenyReason    string   `json:"html_deny_reason"`
-			HTMLDescription   string   `json:"html_description"`
-			HTMLSpecial       string   `json:"html_special"`
-			AgeFrom           *Integer `json:"i_age_from"`
-			AgeTo             *Integer `json:"i_age_to"`
-			Book              Integer  `json:"i_book"`
-			BookActive        Integer  `json:"i_book_active"`
-			Capacity          Integer  `json:"i_capacity"`
-			Duration          Integer  `json:"i_duration"` // In minutes.
-			Wait              Integer  `json:"i_wait"`
-			WaitLimit         *Integer `json:"i_wait_limit"`
-			WaitSpot          Integer  `json:"i_wait_spot"`
-			DenyReasonID      Integer  `json:"id_deny_reason"`
-			IsAgePublic       Bool     `json:"is_age_public"`
-			IsBook            Bool     `json:"is_book"`
-			IsCancel          Bool     `json:"is_cancel"`
-			IsEvent           Bool     `json:"is_event"`
-			IsPromotionOnly   Bool     `json:"is_promotion_only"`
-			VirtualProviderID *Integer `json:"id_virtual_provider"`
-			IsVirtual         Bool     `json:"is_virtual"`
-			IsWait            Bool     `json:"is_wait"`
-			IsWaitList        Bool     `json:"is_wait_list"`
-			IsWaitListEnabled Bool     `json:"is_wait_list_enabled"`
-			ClassID           Integer  `json:"k_class"`
-			Price             Currency `json:"m_price"`
-			HidePrice         Bool     `json:"hide_price"`
-			DurationString    string   `json:"s_duration"`
-			Title             string   `json:"s_title"`
-			RoomText          string   `json:"text_room"`
-			TimezoneString    string   `json:"text_timezone"`
-			VirtualJoinURL    string   `json:"url_virtual_join"`
-		} `json:"a_class"`
-		Location struct {
-			Latitude   Float   `json:"f_latitude"`
-			Longitude  Float   `json:"f_longitude"`
-			Rate       Float   `json:"f_rate"`
-			LocationID Integer `json:"k_location"`
-			Address    string  `json:"s_address"`
-			Map        string  `json:"s_map"`
-			Phone      string  `json:"s_phone"`
-			Title      string  `json:"s_title"`
-		} `json:"a_location"`
-		Staff []struct {
-			StaffID      Integer `json:"k_staff"`
-			Family       string  `json:"s_family"`
-			Name         string  `json:"s_name"`
-			FullName     string  `json:"s_name_full"`
-			Position     string  `json:"s_position"`
-			UID          string  `json:"uid"`
-			XMLBiography string  `json:"xml_biography"`
-		} `json:"a_staff"`
-		// TODO: "a_visits_required"
-		Date          DateTime `json:"dt_date"`
-		ClassPeriodID Integer  `json:"k_class_period"`
-	} `json:"a_session_result"`
-	// TODO: "a_staff"
-	// TODO: "a_visits_required"
+	// These are only set when a single session was requested with "k_class_period" and "dt_date".
+	Assets         []ClassSessionAsset          `json:"a_asset"`
+	Class          *ClassSessionClass           `json:"a_class"`
+	Location       *ClassSessionLocation        `json:"a_location"`
+	Staff          []ClassSessionStaff          `json:"a_staff"`
+	VisitsRequired []ClassSessionVisitsRequired `json:"a_visits_required"`
+
+	// This is set when multiple sessions were requested with "a_session_request".
+	SessionResult []ClassSessionDetail `json:"a_session_result"`
+}
+
+// ClassSessionDetail is the detail of a single class session.
+type ClassSessionDetail struct {
+	Assets         []ClassSessionAsset          `json:"a_asset"`
+	Class          ClassSessionClass            `json:"a_class"`
+	Location       ClassSessionLocation         `json:"a_location"`
+	Staff          []ClassSessionStaff          `json:"a_staff"`
+	VisitsRequired []ClassSessionVisitsRequired `json:"a_visits_required"`
+	Date           DateTime                     `json:"dt_date"` // This is in UTC.
+	ClassPeriodID  Integer                      `json:"k_class_period"`
+}
+
+// ClassSessionAsset is an asset (such as a mat or a bike) that is booked for a class session.
+type ClassSessionAsset struct {
+	Image struct {
+		Height  Integer `json:"i_height"`
+		Width   Integer `json:"i_width"`
+		IsEmpty Bool    `json:"is_empty"`
+		URL     string  `json:"s_url"`
+	} `json:"a_image"`
+	Index   Integer `json:"i_index"` // The number of the asset within its layout.
+	AssetID Integer `json:"k_asset"`
+	Title   string  `json:"s_title"`
 }
 
+// ClassSessionClass is the class of a class session, including its capacity and whether the client can book it.
+type ClassSessionClass struct {
+	ClassTabs []Integer `json:"a_class_tab"`
+	Image     struct {
+		Height  Integer `json:"i_height"`
+		Width   Integer `json:"i_width"`
+		IsEmpty Bool    `json:"is_empty"`
+		IsOwn   Bool    `json:"is_own"`
+		URL     string  `json:"s_url"`
+	} `json:"a_image"`
+	SearchTags        []Integer `json:"a_search_tag"` // The search tag IDs.
+	Tags              []Integer `json:"a_tag"`        // The class tag IDs.
+	CanBook           Bool      `json:"can_book"`
+	GlobalDate        DateTime  `json:"dt_date_global"`
+	LocalDate         DateTime  `json:"dt_date_local"`
+	HTMLDenyReason    string    `json:"html_deny_reason"`
+	HTMLDescription   string    `json:"html_description"`
+	HTMLSpecial       string    `json:"html_special"`
+	AgeFrom           *Integer  `json:"i_age_from"`
+	AgeTo             *Integer  `json:"i_age_to"`
+	Book              Integer   `json:"i_book"`
+	BookActive        Integer   `json:"i_book_active"`
+	Capacity          Integer   `json:"i_capacity"`
+	Duration          Integer   `json:"i_duration"` // In minutes.
+	Wait              Integer   `json:"i_wait"`
+	WaitLimit         *Integer  `json:"i_wait_limit"`
+	WaitSpot          Integer   `json:"i_wait_spot"`
+	DenyReasonID      Integer   `json:"id_deny_reason"`
+	IsAgePublic       Bool      `json:"is_age_public"`
+	IsBook            Bool      `json:"is_book"`
+	IsCancel          Bool      `json:"is_cancel"`
+	IsEvent           Bool      `json:"is_event"`
+	IsPromotionOnly   Bool      `json:"is_promotion_only"`
+	VirtualProviderID *Integer  `json:"id_virtual_provider"`
+	IsVirtual         Bool      `json:"is_virtual"`
+	IsWait            Bool      `json:"is_wait"`
+	IsWaitList        Bool      `json:"is_wait_list"`
+	IsWaitListEnabled Bool      `json:"is_wait_list_enabled"`
+	ClassID           Integer   `json:"k_class"`
+	Price             Currency  `json:"m_price"`
+	HidePrice         Bool      `json:"hide_price"`
+	DurationString    string    `json:"s_duration"`
+	Title             string    `json:"s_title"`
+	RoomText          string    `json:"text_room"`
+	TimezoneString    string    `json:"text_timezone"`
+	VirtualJoinURL    string    `json:"url_virtual_join"`
+}
+
+// ClassSessionLocation is the location of a class session.
+type ClassSessionLocation struct {
+	Latitude   Float   `json:"f_latitude"`
+	Longitude  Float   `json:"f_longitude"`
+	Rate       Float   `json:"f_rate"`
+	LocationID Integer `json:"k_location"`
+	Address    string  `json:"s_address"`
+	Map        string  `json:"s_map"`
+	Phone      string  `json:"s_phone"`
+	Title      string  `json:"s_title"`
+}
+
+// ClassSessionStaff is a staff member who leads a class session.
+type ClassSessionStaff struct {
+	StaffID      Integer `json:"k_staff"`
+	Family       string  `json:"s_family"`
+	Name         string  `json:"s_name"`
+	FullName     string  `json:"s_name_full"`
+	Position     string  `json:"s_position"`
+	UID          string  `json:"uid"`
+	XMLBiography string  `json:"xml_biography"`
+}
+
+// ClassSessionVisitsRequired is a requirement to have already visited another class before booking a class session.
+type ClassSessionVisitsRequired struct {
+	Count   Integer `json:"i_count"` // The number of visits that are required.
+	ClassID Integer `json:"k_class"` // The class that must have been visited.
+	Title   string  `json:"s_title"` // The title of the class that must have been visited.
+}
+
+// TabResponse is the response from "/Wl/Schedule/Tab/Tab.json".
 type TabResponse struct {
 	BaseResponse
 
@@ -336,6 +402,7 @@ type Tab struct {
 	URLOrigin         string   `json:"url_origin"`
 }
 
+// LocationListResponse is the response from "/Wl/Location/List.json".
 type LocationListResponse struct {
 	BaseResponse
 
@@ -362,6 +429,7 @@ type Location struct {
 	AddressRegion  string  `json:"text_region"` // State in the US.
 }
 
+// LocationResponse is the response from "/Wl/Location/View/View.json".
 type LocationResponse struct {
 	BaseResponse
 
@@ -465,6 +533,7 @@ type LocationResponse struct {
 	URLYouTube             string  `json:"url_youtube"`
 }
 
+// AttendanceListResponse is the response from "/Wl/Attendance/AttendanceList.json".
 type AttendanceListResponse struct {
 	BaseResponse
 
@@ -561,101 +630,127 @@ type AttendanceListPerson struct {
 	} `json:"icon"`
 }
 
+// StaffListResponse is the response from "/Wl/Staff/StaffList.json".
 type StaffListResponse struct {
 	BaseResponse
 
-	StaffMap map[string]struct {
-		PayRate      []string `json:"a_pay_rate"`
-		StaffService []struct {
-			ServiceID Integer `json:"k_service"`
-			StaffPay  any     `json:"k_staff_pay"`
-		} `json:"a_staff_service"`
-		Order         Integer `json:"i_order"`
-		IsAppointment Bool    `json:"is_appointment"`
-		IsClass       Bool    `json:"is_class"`
-		IsEvent       Bool    `json:"is_event"`
-		StaffID       Integer `json:"k_staff"`
-		Name          string  `json:"s_name"`  // First name.
-		Image         string  `json:"s_image"` // Always has some value.
-		NameHTML      string  `json:"html_name"`
-		Position      string  `json:"s_position"`
-		Surname       string  `json:"s_surname"` // Last name.
-		SurnameFull   string  `json:"s_surname_full"`
-		NameFull      string  `json:"text_name_full"` // Full name.
-		UID           string  `json:"uid"`
-		ImageURL      string  `json:"url_image"` // Only has the custom value.
-	} `json:"a_staff"` // Indexed by "k_staff"
+	StaffMap map[string]StaffListMember `json:"a_staff"` // Indexed by "k_staff"
+}
+
+// StaffListMember is a staff member in StaffListResponse; see ListStaff.
+type StaffListMember struct {
+	PayRate       []string       `json:"a_pay_rate"`
+	StaffService  []StaffService `json:"a_staff_service"`
+	Order         Integer        `json:"i_order"`
+	IsAppointment Bool           `json:"is_appointment"`
+	IsClass       Bool           `json:"is_class"`
+	IsEvent       Bool           `json:"is_event"`
+	StaffID       Integer        `json:"k_staff"`
+	Name          string         `json:"s_name"`  // First name.
+	Image         string         `json:"s_image"` // Always has some value.
+	NameHTML      string         `json:"html_name"`
+	Position      string         `json:"s_position"`
+	Surname       string         `json:"s_surname"` // Last name.
+	SurnameFull   string         `json:"s_surname_full"`
+	NameFull      string         `json:"text_name_full"` // Full name.
+	UID           string         `json:"uid"`
+	ImageURL      string         `json:"url_image"` // Only has the custom value.
+}
+
+// StaffService is a service that a StaffListMember provides.
+type StaffService struct {
+	ServiceID Integer `json:"k_service"`
+	StaffPay  any     `json:"k_staff_pay"`
 }
 
+// StaffViewResponse is the response from "/Wl/Staff/StaffView/StaffView.json".
 type StaffViewResponse struct {
 	BaseResponse
 
 	// TODO: "a_class_day": []
-	ResultList map[string]struct {
-		// TODO: "a_class_day": []
-		Staff struct {
-			LocationWork []Integer `json:"a_location_work"`
-			Photo        map[string]struct {
-				IDGender     Integer `json:"id_gender"`
-				StaffID      Integer `json:"k_staff"`
-				Name         string  `json:"s_name"` // First name.
-				UID          Integer `json:"uid"`
-				LinkBusiness string  `json:"s_link_business"` // Some kind of internal identifier.
-				LinkWide     string  `json:"s_link_wide"`     // Some kind of internal identifier.
-				Height       Integer `json:"i_height"`
-				Width        Integer `json:"i_width"`
-				IsEmpty      Bool    `json:"is_empty"`
-				URL          string  `json:"s_url"`
-			} `json:"a_photo"` // Indexed by "k_staff" for some unknown reason.
-			BiographyHTML         string   `json:"html_biography"` // This is an HTML fragment.
-			FirstHTML             string   `json:"html_first"`     // First name.
-			LastHTML              string   `json:"html_last"`
-			LocationTitleHTML     string   `json:"html_location_title"`
-			IDGender              Integer  `json:"id_gender"`
-			IsClassesEvents       Bool     `json:"is_classes_events"`
-			IsPublishBusinessPage Bool     `json:"is_publish_business_page"` // True if this should be visible online.
-			IsScheduleEnabled     Bool     `json:"is_schedule_enabled"`
-			LocationID            *Integer `json:"k_location"`
-			StaffID               Integer  `json:"k_staff"`
-			Biography             string   `json:"s_biography"` // This is a whole HTML document, including "<!DOCTYPE html>".
-			Family                string   `json:"s_family"`    // Last name.
-			Name                  string   `json:"s_name"`      // First name.
-			Position              string   `json:"s_position"`
-			BusinessRole          string   `json:"text_business_role"`
-			FullName              string   `json:"text_full_name"`
-			UID                   Integer  `json:"uid"`
-			ScheduleURL           string   `json:"url_schedule"`
-		} `json:"a_staff"`
-	} `json:"a_result_list"` // Indexed by "k_staff"
+	ResultList map[string]StaffViewResult `json:"a_result_list"` // Indexed by "k_staff"
+}
+
+// StaffViewResult is a single staff member in StaffViewResponse.
+type StaffViewResult struct {
+	// TODO: "a_class_day": []
+	Staff StaffProfile `json:"a_staff"`
+}
+
+// StaffProfile is the profile of a staff member in StaffViewResponse; see GetStaff.
+type StaffProfile struct {
+	LocationWork          []Integer             `json:"a_location_work"`
+	Photo                 map[string]StaffPhoto `json:"a_photo"`        // Indexed by "k_staff" for some unknown reason.
+	BiographyHTML         string                `json:"html_biography"` // This is an HTML fragment.
+	FirstHTML             string                `json:"html_first"`     // First name.
+	LastHTML              string                `json:"html_last"`
+	LocationTitleHTML     string                `json:"html_location_title"`
+	IDGender              Integer               `json:"id_gender"`
+	IsClassesEvents       Bool                  `json:"is_classes_events"`
+	IsPublishBusinessPage Bool                  `json:"is_publish_business_page"` // True if this should be visible online.
+	IsScheduleEnabled     Bool                  `json:"is_schedule_enabled"`
+	LocationID            *Integer              `json:"k_location"`
+	StaffID               Integer               `json:"k_staff"`
+	Biography             string                `json:"s_biography"` // This is a whole HTML document, including "<!DOCTYPE html>"; see BiographyBody.
+	Family                string                `json:"s_family"`    // Last name.
+	Name                  string                `json:"s_name"`      // First name.
+	Position              string                `json:"s_position"`
+	BusinessRole          string                `json:"text_business_role"`
+	FullName              string                `json:"text_full_name"`
+	UID                   Integer               `json:"uid"`
+	ScheduleURL           string                `json:"url_schedule"`
+}
+
+// StaffPhoto is a staff member's photo in StaffProfile; see StaffProfile.PhotoURL.
+type StaffPhoto struct {
+	IDGender     Integer `json:"id_gender"`
+	StaffID      Integer `json:"k_staff"`
+	Name         string  `json:"s_name"` // First name.
+	UID          Integer `json:"uid"`
+	LinkBusiness string  `json:"s_link_business"` // Some kind of internal identifier.
+	LinkWide     string  `json:"s_link_wide"`     // Some kind of internal identifier.
+	Height       Integer `json:"i_height"`
+	Width        Integer `json:"i_width"`
+	IsEmpty      Bool    `json:"is_empty"`
+	URL          string  `json:"s_url"`
 }
 
+// ClassesPromotionClassPromotionResponse is the response from "/Wl/Classes/Promotion/ClassPromotion.json".
 type ClassesPromotionClassPromotionResponse struct {
+	BaseResponse
+
 	Promotions []struct {
-		IsSelect Bool `json:"is_select"`
-		// TODO: "id_program": 1,
+		IsSelect     Bool    `json:"is_select"`
+		ProgramID    Integer `json:"id_program"`
 		IsClass      Bool    `json:"is_class"`
 		IsEnrollment Bool    `json:"is_enrollment"`
 		PromotionID  Integer `json:"k_promotion"`
 		TextTitle    string  `json:"text_title"`
 	} `json:"a_promotion"`
-	// TODO: "k_promotion_default": null,
+	DefaultPromotionID *Integer `json:"k_promotion_default"`
 }
 
+// MemberPurchaseMemberByPromotionResponse is the response from "/Wl/Member/Purchase/MemberByPromotion.json".
 type MemberPurchaseMemberByPromotionResponse struct {
+	BaseResponse
+
 	Clients []struct {
 		PurchaseOptions []struct {
 			EndDate          Date     `json:"dl_end"`
 			PurchaseDateTime DateTime `json:"dtu_purchase"`
 			StartDate        Date     `json:"dl_start"`
-			// TODO: "dl_terminate": null,
-			LoginPromotionID Integer `json:"k_login_promotion"`
-			PromotionID      Integer `json:"k_promotion"`
+			TerminateDate    *Date    `json:"dl_terminate"`
+			LoginPromotionID Integer  `json:"k_login_promotion"`
+			PromotionID      Integer  `json:"k_promotion"`
 		} `json:"a_purchase_options"`
 		UID Integer `json:"uid"`
 	} `json:"a_clients"`
 }
 
+// UserInfoUserInfoResponse is the response from "/Wl/User/Info/UserInfo.json".
 type UserInfoUserInfoResponse struct {
+	BaseResponse
+
 	/*
 		"a_member_group": [
 			"49478"
@@ -699,6 +794,12 @@ type UserInfoUserInfoResponse struct {
 			}
 		},
 	*/
+	MemberGroups []Integer `json:"a_member_group"`
+	Photo        struct {
+		Height Integer `json:"i_height"`
+		Width  Integer `json:"i_width"`
+		URL    string  `json:"url_photo"`
+	} `json:"a_photo"`
 	DateAdded DateTime `json:"dt_add"`
 	//TODO: BirthDate *Date `json:"dt_birth"`
 	HasDiscount   Bool    `json:"has_discount"`
@@ -722,6 +823,7 @@ type UserInfoUserInfoResponse struct {
 	PhotoURL       string  `json:"url_photo"`
 }
 
+// ReportData33Response is the response from "/Wl/Report/Data.json" for report 33.
 type ReportData33Response struct {
 	BaseResponse
 	LogID string `json:"k_log"`
@@ -737,22 +839,25 @@ type ReportData33Response struct {
 		ColumnHide struct {
 			Rank bool `json:"s_rank"`
 		}
-		Rows []struct {
-			DataAPI struct {
-				FirstName string   `json:"s_firstname"`
-				LastName  string   `json:"s_lastname"`
-				Email     string   `json:"s_mail"`
-				Name      string   `json:"s_name"`
-				Groups    []string `json:"a_member_group"`
-				PhotoURL  string   `json:"url_photo"`
-			} `json:"a_data_api"`
-			SinceDate struct {
-				Date  DateTime `json:"dt_date"`
-				Class string   `json:"_s_class"`
-			} `json:"dt_since_local"`
-			Note   string  `json:"s_note"`
-			UID    Integer `json:"uid"`
-			Member string  `json:"member"`
-		} `json:"a_row"`
+		Rows []ReportData33Row `json:"a_row"`
 	} `json:"a_data"`
 }
+
+// ReportData33Row is a row of ReportData33Response.
+type ReportData33Row struct {
+	DataAPI struct {
+		FirstName string   `json:"s_firstname"`
+		LastName  string   `json:"s_lastname"`
+		Email     string   `json:"s_mail"`
+		Name      string   `json:"s_name"`
+		Groups    []string `json:"a_member_group"`
+		PhotoURL  string   `json:"url_photo"`
+	} `json:"a_data_api"`
+	SinceDate struct {
+		Date  DateTime `json:"dt_date"`
+		Class string   `json:"_s_class"`
+	} `json:"dt_since_local"`
+	Note   string  `json:"s_note"`
+	UID    Integer `json:"uid"`
+	Member string  `json:"member"`
+}
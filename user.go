@@ -0,0 +1,120 @@
+package wellnessliving
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CurrentUser describes the user that a client is logged in as.
+type CurrentUser struct {
+	UID        Integer                               // The user's ID.
+	Login      string                                // The user's login.
+	Businesses map[Integer]*UserInfoUserInfoResponse // The user's information (including their member groups) in each of the businesses that were asked for, keyed by business ID.
+	Expires    time.Time                             // When the session expires; see CurrentUser for details.  This is zero if it is not known.
+	Username   string                                // The username that the client logged in with, if known.
+}
+
+// UserInfoRequest is the input for "/Wl/User/Info/UserInfo.json".
+type UserInfoRequest struct {
+	BusinessID Integer `wl:"k_business"`
+	UID        Integer `wl:"uid"`
+}
+
+// GetUserInfo returns the information about a user in a business.
+func (c *Client) GetUserInfo(ctx context.Context, input UserInfoRequest) (*UserInfoUserInfoResponse, error) {
+	var output UserInfoUserInfoResponse
+	err := c.Request(ctx, http.MethodGet, "/Wl/User/Info/UserInfo.json", input, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// CurrentUser returns the user that the client is logged in as.
+//
+// The user's information in each of the given businesses (if any) is included.  WellnessLiving has
+// no way to list the businesses that a user belongs to, so the caller must name them.
+//
+// The session expires when the earliest of its cookies that has an expiration time does.  The
+// transient ("t") cookie normally has none (unless the login was "remembered"), since a browser
+// would drop it when it closes; this client keeps it for as long as it keeps the session (see
+// SessionStore), so it is the persistent ("p") cookie that sets the limit.  WellnessLiving may
+// still end the session early.
+//
+// If nobody is logged in, then this returns an error that matches ErrNotAuthenticated.
+func (c *Client) CurrentUser(ctx context.Context, businessIDs ...Integer) (*CurrentUser, error) {
+	// TODO: This path has not been confirmed against the SDK or a recorded cassette.
+	var infoResponse PassportInfoResponse
+	err := c.Request(ctx, http.MethodGet, "/Core/Passport/Login/Info/Info.json", nil, nil, &infoResponse)
+	if err != nil {
+		return nil, err
+	}
+	if infoResponse.UID == nil || *infoResponse.UID == 0 {
+		return nil, fmt.Errorf("%w: nobody is logged in", ErrNotAuthenticated)
+	}
+
+	currentUser := &CurrentUser{
+		UID:        *infoResponse.UID,
+		Login:      infoResponse.Login,
+		Businesses: map[Integer]*UserInfoUserInfoResponse{},
+	}
+	if session := c.Session(); session != nil {
+		currentUser.Username = session.Username
+		for _, cookie := range session.Cookies {
+			switch cookie.Name {
+			case "p", "t":
+				if !cookie.Expires.IsZero() && (currentUser.Expires.IsZero() || cookie.Expires.Before(currentUser.Expires)) {
+					currentUser.Expires = cookie.Expires
+				}
+			}
+		}
+	}
+
+	for _, businessID := range businessIDs {
+		if _, ok := currentUser.Businesses[businessID]; ok {
+			continue
+		}
+		info, err := c.GetUserInfo(ctx, UserInfoRequest{BusinessID: businessID, UID: currentUser.UID})
+		if err != nil {
+			return nil, err
+		}
+		currentUser.Businesses[businessID] = info
+	}
+	return currentUser, nil
+}
+
+// Logout ends the client's session.
+//
+// The session is ended on the WellnessLiving side, the client's cookie jar is emptied, and the
+// session is deleted from the client's SessionStore (if any).
+//
+// The local state is cleared even if WellnessLiving could not be told; in that case, the error
+// from WellnessLiving is returned.
+func (c *Client) Logout(ctx context.Context) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+
+	// TODO: This path has not been confirmed against the SDK or a recorded cassette.
+	logoutErr := c.request(ctx, http.MethodPost, "/Core/Passport/Login/Logout/Logout.json", nil, nil, nil)
+
+	_, err := c.emptyJar()
+	if err != nil {
+		return err
+	}
+	c.username = ""
+	c.authGeneration++
+
+	if c.SessionStore != nil {
+		err = c.SessionStore.DeleteSession(ctx)
+		if err != nil {
+			return errors.Join(logoutErr, err)
+		}
+	}
+	return logoutErr
+}
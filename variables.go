@@ -0,0 +1,225 @@
+package wellnessliving
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EncodeVariables converts the input into the variables that WellnessLiving expects.
+//
+// The input may be nil, a url.Values (which is returned as-is), a map, or a struct (or a pointer to one).
+//
+// Struct fields are encoded using the `wl` tag, which names the WellnessLiving variable:
+//
+//	type Input struct {
+//		BusinessID Integer   `wl:"k_business"`
+//		Date       Date      `wl:"dt_date,omitempty"`
+//		Locations  []Integer `wl:"a_location,omitempty"`
+//	}
+//
+// Fields without a `wl` tag (or with a tag of "-") are skipped, except for embedded structs, whose
+// fields are encoded as if they were part of the outer struct.
+// The "omitempty" option skips the field if it has its zero value.
+// Nil pointers are always skipped.
+//
+// Slices, arrays, maps, and nested structs use WellnessLiving's nested-array notation; for example,
+// `a_location[0]=1&a_location[1]=2` or `a_session[0][k_class_period]=5`.
+//
+// Values are formatted as follows:
+// * Bool and bool: "1" or "0".
+// * Integer and the other integer types (including the SID types): base 10.
+// * Currency: a decimal number with two places.
+// * Date: "2006-01-02".
+// * DateTime: "2006-01-02 15:04:05" in UTC.
+// * Anything that implements encoding.TextMarshaler: its text.
+func EncodeVariables(input interface{}) (url.Values, error) {
+	if input == nil {
+		return url.Values{}, nil
+	}
+	if v, ok := input.(url.Values); ok {
+		return v, nil
+	}
+
+	values := url.Values{}
+	value := reflect.ValueOf(input)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return values, nil
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		err := encodeStruct(values, "", value)
+		if err != nil {
+			return nil, err
+		}
+	case reflect.Map:
+		err := encodeMap(values, "", value)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("wellnessliving: cannot encode variables from %s", value.Type())
+	}
+	return values, nil
+}
+
+// encodeKey returns the name of a nested variable.
+func encodeKey(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "[" + name + "]"
+}
+
+func encodeStruct(values url.Values, prefix string, value reflect.Value) error {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, hasTag := field.Tag.Lookup("wl")
+		if !hasTag {
+			if field.Anonymous {
+				fieldValue := value.Field(i)
+				for fieldValue.Kind() == reflect.Pointer {
+					if fieldValue.IsNil() {
+						break
+					}
+					fieldValue = fieldValue.Elem()
+				}
+				if fieldValue.Kind() == reflect.Struct {
+					err := encodeStruct(values, prefix, fieldValue)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			return fmt.Errorf("wellnessliving: field %q has an empty variable name", field.Name)
+		}
+		omitEmpty := false
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "omitempty":
+				omitEmpty = true
+			}
+		}
+
+		fieldValue := value.Field(i)
+		if omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+		err := encodeValue(values, encodeKey(prefix, name), fieldValue)
+		if err != nil {
+			return fmt.Errorf("wellnessliving: could not encode field %q: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func encodeMap(values url.Values, prefix string, value reflect.Value) error {
+	keys := map[string]reflect.Value{}
+	var names []string
+	iterator := value.MapRange()
+	for iterator.Next() {
+		key := iterator.Key()
+		var name string
+		switch key.Kind() {
+		case reflect.String:
+			name = key.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			name = strconv.FormatInt(key.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			name = strconv.FormatUint(key.Uint(), 10)
+		default:
+			return fmt.Errorf("unsupported map key type: %s", key.Type())
+		}
+		keys[name] = iterator.Value()
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		err := encodeValue(values, encodeKey(prefix, name), keys[name])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeValue(values url.Values, key string, value reflect.Value) error {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case url.Values:
+		for name, list := range v {
+			for _, item := range list {
+				values.Add(encodeKey(key, name), item)
+			}
+		}
+		return nil
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return err
+		}
+		values.Set(key, string(text))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		values.Set(key, value.String())
+	case reflect.Bool:
+		values.Set(key, encodeBool(value.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values.Set(key, strconv.FormatInt(value.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values.Set(key, strconv.FormatUint(value.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Set(key, strconv.FormatFloat(value.Float(), 'f', -1, 64))
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			err := encodeValue(values, encodeKey(key, strconv.Itoa(i)), value.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		return encodeMap(values, key, value)
+	case reflect.Struct:
+		return encodeStruct(values, key, value)
+	default:
+		return fmt.Errorf("unsupported type: %s", value.Type())
+	}
+	return nil
+}
+
+func encodeBool(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,302 @@
+package wellnessliving
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncodeVariables(t *testing.T) {
+	type session struct {
+		ClassPeriodID Integer  `wl:"k_class_period"`
+		Date          DateTime `wl:"dt_date"`
+	}
+	type embedded struct {
+		BusinessID Integer `wl:"k_business"`
+	}
+	five := Integer(5)
+	yes := Bool(true)
+	date := time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC)
+
+	rows := []struct {
+		name     string
+		input    interface{}
+		expected url.Values
+	}{
+		{
+			name:     "Nil",
+			input:    nil,
+			expected: url.Values{},
+		},
+		{
+			name: "Values",
+			input: url.Values{
+				"k_business":   {"1"},
+				"a_location[]": {"1", "2"},
+			},
+			expected: url.Values{
+				"k_business":   {"1"},
+				"a_location[]": {"1", "2"},
+			},
+		},
+		{
+			name: "Scalars",
+			input: struct {
+				String  string   `wl:"s_title"`
+				Int     int      `wl:"i_count"`
+				Uint    uint8    `wl:"i_small"`
+				Float   float64  `wl:"f_rate"`
+				Bool    bool     `wl:"is_active"`
+				Integer Integer  `wl:"k_business"`
+				Yes     Bool     `wl:"is_yes"`
+				No      Bool     `wl:"is_no"`
+				Money   Currency `wl:"m_price"`
+				SID     ModeSID  `wl:"id_mode"`
+			}{
+				String:  "Yoga & Pilates",
+				Int:     -3,
+				Uint:    7,
+				Float:   1.5,
+				Bool:    true,
+				Integer: 123,
+				Yes:     true,
+				No:      false,
+				Money:   12.5,
+				SID:     2,
+			},
+			expected: url.Values{
+				"s_title":    {"Yoga & Pilates"},
+				"i_count":    {"-3"},
+				"i_small":    {"7"},
+				"f_rate":     {"1.5"},
+				"is_active":  {"1"},
+				"k_business": {"123"},
+				"is_yes":     {"1"},
+				"is_no":      {"0"},
+				"m_price":    {"12.50"},
+				"id_mode":    {"2"},
+			},
+		},
+		{
+			name: "Dates",
+			input: struct {
+				Date         Date     `wl:"dl_date"`
+				DateTime     DateTime `wl:"dt_date"`
+				ZeroDate     Date     `wl:"dl_zero"`
+				ZeroDateTime DateTime `wl:"dt_zero"`
+				LocalTime    DateTime `wl:"dt_local"`
+			}{
+				Date:      Date{Time: date},
+				DateTime:  DateTime{Time: date},
+				LocalTime: DateTime{Time: date.In(time.FixedZone("EST", -5*60*60))},
+			},
+			expected: url.Values{
+				"dl_date":  {"2024-03-01"},
+				"dt_date":  {"2024-03-01 10:30:00"},
+				"dl_zero":  {""},
+				"dt_zero":  {""},
+				"dt_local": {"2024-03-01 10:30:00"},
+			},
+		},
+		{
+			name: "OmitEmpty",
+			input: struct {
+				Empty    Integer   `wl:"k_empty,omitempty"`
+				Set      Integer   `wl:"k_set,omitempty"`
+				Kept     Integer   `wl:"k_kept"`
+				NoDate   Date      `wl:"dl_date,omitempty"`
+				NoList   []Integer `wl:"a_list,omitempty"`
+				NoString string    `wl:"s_title,omitempty"`
+				False    Bool      `wl:"is_false,omitempty"`
+			}{
+				Set: 5,
+			},
+			expected: url.Values{
+				"k_set":  {"5"},
+				"k_kept": {"0"},
+			},
+		},
+		{
+			name: "Pointers",
+			input: &struct {
+				Nil     *Integer `wl:"k_nil"`
+				Set     *Integer `wl:"k_set"`
+				Bool    *Bool    `wl:"is_set"`
+				NilBool *Bool    `wl:"is_nil,omitempty"`
+			}{
+				Set:  &five,
+				Bool: &yes,
+			},
+			expected: url.Values{
+				"k_set":  {"5"},
+				"is_set": {"1"},
+			},
+		},
+		{
+			name:     "NilPointer",
+			input:    (*struct{})(nil),
+			expected: url.Values{},
+		},
+		{
+			name: "Skipped",
+			input: struct {
+				embedded
+				unexported Integer `wl:"k_unexported"`
+				Untagged   Integer
+				Dash       Integer `wl:"-"`
+				Tagged     Integer `wl:"k_tagged"`
+			}{
+				embedded:   embedded{BusinessID: 1},
+				unexported: 2,
+				Untagged:   3,
+				Dash:       4,
+				Tagged:     5,
+			},
+			expected: url.Values{
+				"k_tagged": {"5"},
+			},
+		},
+		{
+			name: "Embedded",
+			input: struct {
+				EncodeVariablesEmbedded
+				*EncodeVariablesPointer
+				Tagged Integer `wl:"k_tagged"`
+			}{
+				EncodeVariablesEmbedded: EncodeVariablesEmbedded{BusinessID: 1},
+				Tagged:                  2,
+			},
+			expected: url.Values{
+				"k_business": {"1"},
+				"k_tagged":   {"2"},
+			},
+		},
+		{
+			name: "Slices",
+			input: struct {
+				Locations []Integer  `wl:"a_location"`
+				Array     [2]string  `wl:"a_array"`
+				Sessions  []session  `wl:"a_session"`
+				Nested    [][]Bool   `wl:"a_nested"`
+				Empty     []Integer  `wl:"a_empty"`
+				Pointers  []*Integer `wl:"a_pointer"`
+			}{
+				Locations: []Integer{10, 20},
+				Array:     [2]string{"x", "y"},
+				Sessions: []session{
+					{ClassPeriodID: 5, Date: DateTime{Time: date}},
+					{ClassPeriodID: 6, Date: DateTime{Time: date.Add(time.Hour)}},
+				},
+				Nested:   [][]Bool{{true}, {false, true}},
+				Pointers: []*Integer{&five, nil},
+			},
+			expected: url.Values{
+				"a_location[0]":                {"10"},
+				"a_location[1]":                {"20"},
+				"a_array[0]":                   {"x"},
+				"a_array[1]":                   {"y"},
+				"a_session[0][k_class_period]": {"5"},
+				"a_session[0][dt_date]":        {"2024-03-01 10:30:00"},
+				"a_session[1][k_class_period]": {"6"},
+				"a_session[1][dt_date]":        {"2024-03-01 11:30:00"},
+				"a_nested[0][0]":               {"1"},
+				"a_nested[1][0]":               {"0"},
+				"a_nested[1][1]":               {"1"},
+				"a_pointer[0]":                 {"5"},
+			},
+		},
+		{
+			name: "NestedStruct",
+			input: struct {
+				Session  session  `wl:"a_session"`
+				Optional *session `wl:"a_optional"`
+				Missing  *session `wl:"a_missing"`
+			}{
+				Session:  session{ClassPeriodID: 5},
+				Optional: &session{ClassPeriodID: 6},
+			},
+			expected: url.Values{
+				"a_session[k_class_period]":  {"5"},
+				"a_session[dt_date]":         {""},
+				"a_optional[k_class_period]": {"6"},
+				"a_optional[dt_date]":        {""},
+			},
+		},
+		{
+			name: "Maps",
+			input: struct {
+				ByName  map[string]Integer `wl:"a_name"`
+				ByID    map[Integer]string `wl:"a_id"`
+				Filters url.Values         `wl:"a_filter"`
+			}{
+				ByName:  map[string]Integer{"b": 2, "a": 1},
+				ByID:    map[Integer]string{7: "x"},
+				Filters: url.Values{"s_text": {"yoga"}},
+			},
+			expected: url.Values{
+				"a_name[a]":        {"1"},
+				"a_name[b]":        {"2"},
+				"a_id[7]":          {"x"},
+				"a_filter[s_text]": {"yoga"},
+			},
+		},
+		{
+			name:  "TopLevelMap",
+			input: map[string]interface{}{"k_business": Integer(1), "a_location": []Integer{2, 3}},
+			expected: url.Values{
+				"k_business":    {"1"},
+				"a_location[0]": {"2"},
+				"a_location[1]": {"3"},
+			},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.name, func(t *testing.T) {
+			actual, err := EncodeVariables(row.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, row.expected) {
+				t.Errorf("Expected %v, got %v", row.expected, actual)
+			}
+		})
+	}
+}
+
+// EncodeVariablesEmbedded is embedded in a test struct; it must be exported for its fields to be encoded.
+type EncodeVariablesEmbedded struct {
+	BusinessID Integer `wl:"k_business"`
+}
+
+// EncodeVariablesPointer is embedded (as a nil pointer) in a test struct.
+type EncodeVariablesPointer struct {
+	LocationID Integer `wl:"k_location"`
+}
+
+func TestEncodeVariablesErrors(t *testing.T) {
+	rows := []struct {
+		name  string
+		input interface{}
+	}{
+		{"String", "k_business=1"},
+		{"Slice", []Integer{1}},
+		{"EmptyName", struct {
+			BusinessID Integer `wl:",omitempty"`
+		}{}},
+		{"UnsupportedType", struct {
+			Callback func() `wl:"f_callback"`
+		}{Callback: func() {}}},
+		{"UnsupportedKey", struct {
+			Map map[float64]string `wl:"a_map"`
+		}{Map: map[float64]string{1.5: "x"}}},
+	}
+	for _, row := range rows {
+		t.Run(row.name, func(t *testing.T) {
+			_, err := EncodeVariables(row.input)
+			if err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
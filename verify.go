@@ -0,0 +1,161 @@
+package wellnessliving
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VerifyReason is the reason that a signature could not be verified.
+type VerifyReason string
+
+const (
+	VerifyReasonMissingAuthorization   VerifyReason = "missing-authorization"   // There is no "Authorization" header.
+	VerifyReasonMalformedAuthorization VerifyReason = "malformed-authorization" // The "Authorization" header is not in the "20150518,<id>,,<hash>" format.
+	VerifyReasonUnknownID              VerifyReason = "unknown-id"              // The authorization ID is not known.
+	VerifyReasonMissingDate            VerifyReason = "missing-date"            // There is no "Date" header.
+	VerifyReasonMalformedDate          VerifyReason = "malformed-date"          // The "Date" header could not be parsed.
+	VerifyReasonClockSkew              VerifyReason = "clock-skew"              // The "Date" header is too far from the current time.
+	VerifyReasonMismatch               VerifyReason = "mismatch"                // The signature does not match the request.
+)
+
+// VerifyError is returned when a signature could not be verified.
+//
+// This matches ErrSignatureInvalid.
+type VerifyError struct {
+	Reason VerifyReason // The reason that verification failed.
+	Detail string       // A human-readable description of the problem.
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("wellnessliving: signature invalid: %s: %s", e.Reason, e.Detail)
+}
+
+func (e *VerifyError) Is(target error) bool {
+	return target == ErrSignatureInvalid
+}
+
+// VerifyOptions are the options for Verify and VerifyMiddleware.
+type VerifyOptions struct {
+	// AuthorizationCode returns the authorization code for an authorization ID, and whether or not the ID is known.
+	AuthorizationCode func(authorizationID string) (string, bool)
+
+	MaxClockSkew  time.Duration // The maximum difference between the "Date" header and the current time.  If zero, this defaults to 5 minutes.
+	Clock         Clock         // If set, this is used instead of the system clock.
+	SignedHeaders []string      // The names of the headers that are part of the signature; see Client.SignedHeaders.
+}
+
+// Verify checks the "Authorization" header of a request that was signed the way that WellnessLiving
+// requires (for example, by a Client).
+//
+// The signature is recomputed from the request's method, host, path, query variables, "p" and "t"
+// cookies, and "Date" header.
+//
+// If the signature is not valid, then this returns a *VerifyError.
+func Verify(r *http.Request, options VerifyOptions) error {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return &VerifyError{Reason: VerifyReasonMissingAuthorization, Detail: "the Authorization header is missing"}
+	}
+	parts := strings.Split(authorization, ",")
+	if len(parts) != 4 || parts[0] != "20150518" || parts[1] == "" || parts[3] == "" {
+		return &VerifyError{Reason: VerifyReasonMalformedAuthorization, Detail: fmt.Sprintf("the Authorization header is not valid: %q", authorization)}
+	}
+	authorizationID := parts[1]
+	hash := parts[3]
+
+	var authorizationCode string
+	var ok bool
+	if options.AuthorizationCode != nil {
+		authorizationCode, ok = options.AuthorizationCode(authorizationID)
+	}
+	if !ok {
+		return &VerifyError{Reason: VerifyReasonUnknownID, Detail: fmt.Sprintf("the authorization ID is not known: %q", authorizationID)}
+	}
+
+	dateString := r.Header.Get("Date")
+	if dateString == "" {
+		return &VerifyError{Reason: VerifyReasonMissingDate, Detail: "the Date header is missing"}
+	}
+	date, err := http.ParseTime(dateString)
+	if err != nil {
+		return &VerifyError{Reason: VerifyReasonMalformedDate, Detail: fmt.Sprintf("the Date header is not valid: %q", dateString)}
+	}
+
+	maxClockSkew := options.MaxClockSkew
+	if maxClockSkew <= 0 {
+		maxClockSkew = 5 * time.Minute
+	}
+	var now time.Time
+	if options.Clock != nil {
+		now = options.Clock.Now()
+	} else {
+		now = time.Now()
+	}
+	skew := now.Sub(date)
+	if skew > maxClockSkew || skew < -maxClockSkew {
+		return &VerifyError{Reason: VerifyReasonClockSkew, Detail: fmt.Sprintf("the Date header is %v away from the current time", skew.Round(time.Second))}
+	}
+
+	signature := Signature{
+		Header:            http.Header{},
+		Variables:         r.URL.Query(),
+		Time:              date.UTC(),
+		AuthorizationCode: authorizationCode,
+		Host:              r.Host,
+		AuthorizationID:   authorizationID,
+		Method:            strings.ToUpper(r.Method),
+		Resource:          strings.TrimLeft(r.URL.Path, "/"),
+	}
+	if cookie, err := r.Cookie("p"); err == nil {
+		signature.CookiePersistent = cookie.Value
+	}
+	if cookie, err := r.Cookie("t"); err == nil {
+		signature.CookieTransient = cookie.Value
+	}
+	for _, key := range options.SignedHeaders {
+		for _, value := range r.Header.Values(key) {
+			signature.Header.Add(key, value)
+		}
+	}
+
+	expected := signatureCompute(signature)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(hash)) != 1 {
+		return &VerifyError{Reason: VerifyReasonMismatch, Detail: "the signature does not match the request"}
+	}
+	return nil
+}
+
+// verifyErrorStatus is the envelope status that VerifyMiddleware responds with.
+//
+// This is this package's own code; it is not known to be what the WellnessLiving API uses.
+const verifyErrorStatus = "signature-invalid"
+
+// VerifyMiddleware returns a handler that verifies the signature of every request (see Verify)
+// before passing it to next.
+//
+// Requests that fail verification get a "401 Unauthorized" response with a WellnessLiving-style
+// error envelope whose status is "signature-invalid".
+func VerifyMiddleware(options VerifyOptions, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := Verify(r, options)
+		if err != nil {
+			reason := ""
+			if verifyError, ok := err.(*VerifyError); ok {
+				reason = string(verifyError.Reason)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  verifyErrorStatus,
+				"class":   reason,
+				"message": err.Error(),
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,446 @@
+// Package wltest provides a fake WellnessLiving API server for tests.
+//
+// The server checks request signatures the way that the real API does, supports the Notepad/Enter
+// login handshake (with the "p" and "t" cookies), and serves canned fixtures for the common
+// endpoints (including two pages of report 33).  Tests can replace any fixture and inject error
+// envelopes or HTTP failures.
+//
+//	server := wltest.NewServer()
+//	defer server.Close()
+//	server.AddUser("jane@example.com", "secret")
+//
+//	client := server.Client()
+//	err := client.Login(ctx, "jane@example.com", "secret")
+package wltest
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tekkamanendless/wellnessliving"
+)
+
+// These are the lifetimes of the cookies, in seconds.
+const (
+	persistentAge        = 365 * 24 * 60 * 60 // The persistent ("p") cookie.
+	transientRememberAge = 30 * 24 * 60 * 60  // The transient ("t") cookie, when the login is "remembered".  Otherwise, it is a session cookie.
+)
+
+//go:embed fixtures/*.json
+var fixtureFiles embed.FS
+
+// defaultFixtures maps each endpoint to the fixture file that it serves by default.
+//
+// A key with an "i_page" query is the fixture for just that page; see SetFixture.
+var defaultFixtures = map[string]string{
+	"/Wl/Event/EventList.json":              "fixtures/event-list.json",
+	"/Wl/Location/List.json":                "fixtures/location-list.json",
+	"/Wl/Location/View/View.json":           "fixtures/location-view.json",
+	"/Wl/Schedule/Tab/Tab.json":             "fixtures/schedule-tab.json",
+	"/Wl/Schedule/ClassList/ClassList.json": "fixtures/class-list.json",
+	"/Wl/Schedule/ClassView/ClassView.json": "fixtures/class-view.json",
+	"/Wl/Attendance/AttendanceList.json":    "fixtures/attendance-list.json",
+	"/Wl/Staff/StaffList.json":              "fixtures/staff-list.json",
+	"/Wl/Staff/StaffView/StaffView.json":    "fixtures/staff-view.json",
+	"/Wl/Report/Data.json?i_page=0":         "fixtures/report-data-33-0.json",
+	"/Wl/Report/Data.json?i_page=1":         "fixtures/report-data-33-1.json",
+}
+
+// Failure is an injected failure; see Server.Fail.
+type Failure struct {
+	StatusCode int                           // The HTTP status code.  If zero, this is 200.
+	Header     http.Header                   // Any extra headers, such as "Retry-After".
+	Error      *wellnessliving.ErrorResponse // If set, this is the body.  If its status is empty, it is "error".
+	Body       string                        // If Error is not set, this is the body.
+	Times      int                           // The number of requests that fail this way.  If zero, this is 1.
+}
+
+// user is a user that can log in.
+type user struct {
+	uid      int
+	password string
+}
+
+// Server is a fake WellnessLiving API server.
+type Server struct {
+	*httptest.Server
+
+	AuthorizationCode string // The authorization code that clients must sign requests with.
+	AuthorizationID   string // The authorization ID that clients must sign requests with.
+
+	mutex        sync.Mutex
+	users        map[string]*user         // Keyed by login.
+	notepads     map[string]bool          // The notepads that have been handed out and not yet used.
+	sessions     map[string]string        // The logins, keyed by transient ("t") cookie.
+	fixtures     map[string][]byte        // The response bodies, keyed by path.
+	handlers     map[string]http.Handler  // Custom handlers, keyed by path.
+	failures     map[string][]*Failure    // The pending failures, keyed by path.
+	requireLogin map[string]bool          // The paths that require a login.
+	requests     map[string]int           // The number of requests, keyed by path.
+	lastRequest  map[string]*http.Request // The last request, keyed by path.
+	nextUID      int
+}
+
+// NewServer starts a new server.
+//
+// The caller must call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		AuthorizationCode: randomString(),
+		AuthorizationID:   randomString(),
+		users:             map[string]*user{},
+		notepads:          map[string]bool{},
+		sessions:          map[string]string{},
+		fixtures:          map[string][]byte{},
+		handlers:          map[string]http.Handler{},
+		failures:          map[string][]*Failure{},
+		requireLogin:      map[string]bool{},
+		requests:          map[string]int{},
+		lastRequest:       map[string]*http.Request{},
+		nextUID:           9000,
+	}
+	for path, filename := range defaultFixtures {
+		contents, err := fixtureFiles.ReadFile(filename)
+		if err != nil {
+			panic(err)
+		}
+		s.fixtures[path] = contents
+	}
+
+	verifyOptions := wellnessliving.VerifyOptions{
+		AuthorizationCode: func(authorizationID string) (string, bool) {
+			if authorizationID != s.AuthorizationID {
+				return "", false
+			}
+			return s.AuthorizationCode, true
+		},
+	}
+	s.Server = httptest.NewServer(wellnessliving.VerifyMiddleware(verifyOptions, http.HandlerFunc(s.serveHTTP)))
+	return s
+}
+
+// Client returns a new client that is set up to talk to this server.
+func (s *Server) Client() *wellnessliving.Client {
+	client := &wellnessliving.Client{
+		URL: s.URL,
+		Credentials: wellnessliving.StaticCredentials{
+			AuthorizationCode: s.AuthorizationCode,
+			AuthorizationID:   s.AuthorizationID,
+		},
+	}
+	client.HTTPClient.Transport = s.Server.Client().Transport
+	return client
+}
+
+// AddUser adds a user that can log in, and returns the user's UID.
+func (s *Server) AddUser(login string, password string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.nextUID++
+	s.users[login] = &user{
+		uid:      s.nextUID,
+		password: password,
+	}
+	return s.nextUID
+}
+
+// ExpireSessions ends every session, as if they had all timed out.
+func (s *Server) ExpireSessions() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sessions = map[string]string{}
+}
+
+// RequireLogin makes the given paths require a logged-in user.
+func (s *Server) RequireLogin(paths ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, path := range paths {
+		s.requireLogin[path] = true
+	}
+}
+
+// SetFixture sets the response for a path.  The response is encoded as JSON, unless it is already a []byte or a string.
+//
+// For paged endpoints, the path may end with "?i_page=N" to set the response for just that page;
+// a page without its own fixture gets the fixture for the path (if there is one).
+func (s *Server) SetFixture(path string, response interface{}) {
+	var contents []byte
+	switch v := response.(type) {
+	case []byte:
+		contents = v
+	case string:
+		contents = []byte(v)
+	default:
+		var err error
+		contents, err = json.Marshal(v)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.fixtures[path] = contents
+}
+
+// Handle sets a custom handler for a path.  The signature will already have been verified.
+func (s *Server) Handle(path string, handler http.Handler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.handlers[path] = handler
+}
+
+// Fail makes the next request(s) to the path fail.
+//
+// Failures for the same path are used in the order that they were added.
+func (s *Server) Fail(path string, failure Failure) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if failure.Times <= 0 {
+		failure.Times = 1
+	}
+	s.failures[path] = append(s.failures[path], &failure)
+}
+
+// Requests returns the number of requests that have been made to the path (including the ones that failed).
+func (s *Server) Requests(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests[path]
+}
+
+// LastRequest returns the last request that was made to the path, or nil.
+//
+// The request's form has already been parsed.
+func (s *Server) LastRequest(path string) *http.Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.lastRequest[path]
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+
+	s.mutex.Lock()
+	s.requests[r.URL.Path]++
+	s.lastRequest[r.URL.Path] = r
+	var failure *Failure
+	if failures := s.failures[r.URL.Path]; len(failures) > 0 {
+		failure = failures[0]
+		failure.Times--
+		if failure.Times <= 0 {
+			s.failures[r.URL.Path] = failures[1:]
+		}
+	}
+	handler := s.handlers[r.URL.Path]
+	fixture, hasFixture := s.fixtures[r.URL.Path+"?i_page="+r.Form.Get("i_page")]
+	if !hasFixture {
+		fixture, hasFixture = s.fixtures[r.URL.Path]
+	}
+	requireLogin := s.requireLogin[r.URL.Path]
+	s.mutex.Unlock()
+
+	if failure != nil {
+		s.writeFailure(w, failure)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/Core/Passport/Login/Enter/Notepad.json":
+		s.serveNotepad(w, r)
+		return
+	case "/Core/Passport/Login/Enter/Enter.json":
+		s.serveEnter(w, r)
+		return
+	case "/Core/Passport/Login/Logout/Logout.json":
+		s.serveLogout(w, r)
+		return
+	case "/Core/Passport/Login/Info/Info.json":
+		s.serveInfo(w, r)
+		return
+	}
+
+	if requireLogin && s.currentLogin(r) == "" {
+		writeError(w, http.StatusUnauthorized, "passport-login-required", "You must be logged in.")
+		return
+	}
+	if handler != nil {
+		handler.ServeHTTP(w, r)
+		return
+	}
+	if !hasFixture {
+		writeError(w, http.StatusNotFound, "error", "There is no such resource: "+r.URL.Path)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(fixture)
+}
+
+func (s *Server) serveNotepad(w http.ResponseWriter, r *http.Request) {
+	ensureCookie(w, r, "p")
+
+	notepad := randomString()
+	s.mutex.Lock()
+	s.notepads[notepad] = true
+	s.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":    "ok",
+		"id_region": strconv.Itoa(int(wellnessliving.RegionSIDUSEast1)),
+		"s_hash":    "sha3",
+		"s_notepad": notepad,
+	})
+}
+
+func (s *Server) serveEnter(w http.ResponseWriter, r *http.Request) {
+	login := r.Form.Get("s_login")
+	notepad := r.Form.Get("s_notepad")
+
+	s.mutex.Lock()
+	validNotepad := s.notepads[notepad]
+	delete(s.notepads, notepad)
+	u := s.users[login]
+	s.mutex.Unlock()
+
+	if !validNotepad {
+		writeError(w, http.StatusBadRequest, "passport-login-notepad", "The notepad is not valid.")
+		return
+	}
+	if u == nil {
+		writeError(w, http.StatusUnauthorized, "passport-login-wrong", "The login or password is wrong.")
+		return
+	}
+	expected, err := wellnessliving.HashPassword("sha3", notepad, u.password)
+	if err != nil || expected != r.Form.Get("s_password") {
+		writeError(w, http.StatusUnauthorized, "passport-login-wrong", "The login or password is wrong.")
+		return
+	}
+
+	transient := randomString()
+	s.mutex.Lock()
+	s.sessions[transient] = login
+	s.mutex.Unlock()
+
+	ensureCookie(w, r, "p")
+	transientCookie := &http.Cookie{Name: "t", Value: transient, Path: "/"}
+	if r.Form.Get("s_remember") != "" {
+		// A "remembered" login outlives the browser.
+		transientCookie.MaxAge = transientRememberAge
+	}
+	http.SetCookie(w, transientCookie)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":       "ok",
+		"url_redirect": "",
+	})
+}
+
+func (s *Server) serveLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie("t"); err == nil {
+		s.mutex.Lock()
+		delete(s.sessions, cookie.Value)
+		s.mutex.Unlock()
+	}
+	http.SetCookie(w, &http.Cookie{Name: "t", Value: "", Path: "/", MaxAge: -1})
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+}
+
+func (s *Server) serveInfo(w http.ResponseWriter, r *http.Request) {
+	login := s.currentLogin(r)
+
+	response := map[string]interface{}{
+		"status":  "ok",
+		"uid":     nil,
+		"s_login": "",
+	}
+	if login != "" {
+		s.mutex.Lock()
+		response["uid"] = strconv.Itoa(s.users[login].uid)
+		s.mutex.Unlock()
+		response["s_login"] = login
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// currentLogin returns the login of the user that the request is authenticated as, if any.
+func (s *Server) currentLogin(r *http.Request) string {
+	cookie, err := r.Cookie("t")
+	if err != nil {
+		return ""
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.sessions[cookie.Value]
+}
+
+func (s *Server) writeFailure(w http.ResponseWriter, failure *Failure) {
+	for key, values := range failure.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	statusCode := failure.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	if failure.Error != nil {
+		errorResponse := *failure.Error
+		if errorResponse.Status == "" {
+			errorResponse.Status = "error"
+		}
+		writeJSON(w, statusCode, errorResponse)
+		return
+	}
+	w.WriteHeader(statusCode)
+	_, _ = w.Write([]byte(failure.Body))
+}
+
+// ensureCookie sets the cookie to a random value if the request doesn't already have it.
+//
+// The cookie lasts for as long as the persistent ("p") cookie does.
+func ensureCookie(w http.ResponseWriter, r *http.Request, name string) {
+	if cookie, err := r.Cookie(name); err == nil && cookie.Value != "" {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: name, Value: randomString(), Path: "/", MaxAge: persistentAge})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, status string, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"status":  status,
+		"message": message,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// randomString returns a random hexadecimal string.
+func randomString() string {
+	contents := make([]byte, 16)
+	_, err := rand.Read(contents)
+	if err != nil {
+		panic(err)
+	}
+	return strings.ToLower(hex.EncodeToString(contents))
+}